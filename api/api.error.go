@@ -0,0 +1,153 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	HeaderRequestID = "X-Request-Id"
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrConflict     = errors.New("conflict")
+)
+
+// Error is the error returned by the API for a non-2xx response.
+//
+// It can be classified with errors.Is, e.g. errors.Is(err, api.ErrNotFound).
+//
+type Error struct {
+	StatusCode int
+	RequestID  string
+	Messages   []string
+}
+
+// Error returns the status and the messages of the error.
+//
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("[%d %s]", e.StatusCode, http.StatusText(e.StatusCode)))
+	if len(e.Messages) > 0 {
+		b.WriteString(" ")
+		b.WriteString(strings.Join(e.Messages, "; "))
+	}
+	if len(e.RequestID) > 0 {
+		b.WriteString(fmt.Sprintf(" (request id: %s)", e.RequestID))
+	}
+	return b.String()
+}
+
+// Is reports whether the status of the error matches one of the sentinel errors.
+//
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+// NewError returns an *Error of the response, or nil when the response is not an error.
+//
+// The body of the response is read and left readable.
+//
+func NewError(res *Response) error {
+	if !res.IsError() {
+		return nil
+	}
+
+	e := &Error{
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get(HeaderRequestID),
+	}
+
+	if res.Body != nil {
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if err == nil {
+			e.Messages = parseErrorMessages(body)
+		}
+	}
+
+	return e
+}
+
+// parseErrorMessages extracts the messages of an error body, which is either
+// {"errors": [...]}, {"errors": {"field": [...]}} or {"error": "..."}.
+//
+func parseErrorMessages(body []byte) []string {
+	var r struct {
+		Errors json.RawMessage `json:"errors"`
+		Error  string          `json:"error"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		if s := strings.TrimSpace(string(body)); len(s) > 0 {
+			return []string{s}
+		}
+		return nil
+	}
+
+	var messages []string
+	if len(r.Error) > 0 {
+		messages = append(messages, r.Error)
+	}
+
+	var list []string
+	if err := json.Unmarshal(r.Errors, &list); err == nil {
+		return append(messages, list...)
+	}
+
+	var fields map[string][]string
+	if err := json.Unmarshal(r.Errors, &fields); err == nil {
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, e := range fields[name] {
+				messages = append(messages, name+": "+e)
+			}
+		}
+	}
+	return messages
+}
+
+// WithErrors wraps a Transport so that every request returns an *Error for a non-2xx response.
+//
+func WithErrors(t Transport) Transport {
+	return errorTransport{t}
+}
+
+type errorTransport struct {
+	Transport
+}
+
+func (t errorTransport) Perform(req *http.Request) (*http.Response, error) {
+	res, err := t.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := NewError(&Response{StatusCode: res.StatusCode, Header: res.Header, Body: res.Body}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -12,7 +12,8 @@ import (
 )
 
 const (
-	HeaderContentType = "Content-Type"
+	HeaderAuthorization = "Authorization"
+	HeaderContentType   = "Content-Type"
 )
 
 var (
@@ -103,6 +104,8 @@ func (r *Response) HasWarnings() bool {
 type Request struct {
 	Context   context.Context
 	Transport Transport
+	// Auth is an App Search key, e.g. a search key, authenticating the request instead of the credentials of the client.
+	Auth string
 }
 
 // NewRequest creates an HTTP request.
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var engineNameRegexp = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// Path joins the segments to the prefix, every segment is escaped so it can
+// contain characters like '/', '?' or '#'.
+//
+func Path(prefix string, segments ...string) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(prefix, "/"))
+	for _, s := range segments {
+		b.WriteByte('/')
+		b.WriteString(url.PathEscape(s))
+	}
+	return b.String()
+}
+
+// ValidateEngineName returns an error if the name is not a valid App Search engine name,
+// which may only contain lowercase letters, numbers and hyphens.
+//
+func ValidateEngineName(name string) error {
+	if !engineNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid engine name %q: only lowercase letters, numbers and hyphens are allowed", name)
+	}
+	return nil
+}
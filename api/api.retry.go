@@ -0,0 +1,228 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderRetryAfter = "Retry-After"
+)
+
+const (
+	// DefaultMaxRetries is the number of retries when RetryConfig.MaxRetries is not set.
+	DefaultMaxRetries = 3
+	// DefaultMaxRetryAfter is the longest delay taken from Retry-After when RetryConfig.MaxRetryAfter is not set.
+	DefaultMaxRetryAfter = 30 * time.Second
+)
+
+// DefaultRetryOnStatus holds the statuses retried when RetryConfig.RetryOnStatus is not set.
+//
+var DefaultRetryOnStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryOnMethods holds the methods retried after a network error when RetryConfig.RetryOnMethods is not set,
+// other methods may have been handled by the server before the error, e.g. a POST creating a resource.
+//
+var DefaultRetryOnMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+// RetryConfig configures the retrying of failed requests.
+//
+type RetryConfig struct {
+	// Disable disables retrying.
+	Disable bool
+	// MaxRetries is the number of retries after the first attempt, defaults to DefaultMaxRetries.
+	MaxRetries int
+	// RetryOnStatus holds the statuses to retry, defaults to DefaultRetryOnStatus.
+	RetryOnStatus []int
+	// RetryOnMethods holds the methods to retry after a network error, defaults to DefaultRetryOnMethods.
+	// The retry statuses are retried for every method.
+	RetryOnMethods []string
+	// Backoff returns the delay before the given retry, defaults to DefaultRetryBackoff.
+	// The Retry-After header of the response takes precedence over it.
+	Backoff func(attempt int) time.Duration
+	// MaxRetryAfter caps the delay taken from Retry-After, defaults to DefaultMaxRetryAfter.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryBackoff doubles the delay on every retry, starting from 100ms up to 10s.
+//
+func DefaultRetryBackoff(attempt int) time.Duration {
+	d := 100 * time.Millisecond
+	for i := 1; i < attempt && d < 10*time.Second; i++ {
+		d *= 2
+	}
+	if d > 10*time.Second {
+		d = 10 * time.Second
+	}
+	return d
+}
+
+type retryContextKey struct{}
+
+// ContextWithRetry returns a context overriding the retry configuration of the requests made with it,
+// the options left unset are taken from the transport, e.g. Disable turns off retrying for those requests.
+//
+func ContextWithRetry(ctx context.Context, cfg RetryConfig) context.Context {
+	return context.WithValue(ctx, retryContextKey{}, cfg)
+}
+
+// WithRetry wraps a Transport so that requests failing with one of the retry statuses, or
+// with a network error for one of the retry methods, are sent again. Request bodies are buffered to be replayed.
+//
+func WithRetry(t Transport, cfg RetryConfig) Transport {
+	return retryTransport{Transport: t, config: cfg}
+}
+
+type retryTransport struct {
+	Transport
+	config RetryConfig
+}
+
+func (t retryTransport) Perform(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	cfg := t.config
+	if override, ok := ctx.Value(retryContextKey{}).(RetryConfig); ok {
+		cfg.Disable = cfg.Disable || override.Disable
+		if override.MaxRetries > 0 {
+			cfg.MaxRetries = override.MaxRetries
+		}
+		if override.RetryOnStatus != nil {
+			cfg.RetryOnStatus = override.RetryOnStatus
+		}
+		if override.RetryOnMethods != nil {
+			cfg.RetryOnMethods = override.RetryOnMethods
+		}
+		if override.Backoff != nil {
+			cfg.Backoff = override.Backoff
+		}
+		if override.MaxRetryAfter > 0 {
+			cfg.MaxRetryAfter = override.MaxRetryAfter
+		}
+	}
+	if cfg.Disable {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.RetryOnStatus == nil {
+		cfg.RetryOnStatus = DefaultRetryOnStatus
+	}
+	if cfg.RetryOnMethods == nil {
+		cfg.RetryOnMethods = DefaultRetryOnMethods
+	}
+	if cfg.Backoff == nil {
+		cfg.Backoff = DefaultRetryBackoff
+	}
+	if cfg.MaxRetryAfter <= 0 {
+		cfg.MaxRetryAfter = DefaultMaxRetryAfter
+	}
+
+	if cfg.MaxRetries > 0 && req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.Body, _ = req.GetBody()
+	}
+
+	for attempt := 0; ; attempt++ {
+		// the transport may update the request, every attempt is sent with a fresh copy
+		r := req
+		if cfg.MaxRetries > 0 {
+			r = req.Clone(ctx)
+			if attempt > 0 && req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		res, err := t.Transport.Perform(r)
+		if attempt >= cfg.MaxRetries || !shouldRetry(cfg, req.Method, res, err) {
+			return res, err
+		}
+
+		delay := cfg.Backoff(attempt + 1)
+		if res != nil {
+			if d, ok := retryAfter(res.Header); ok {
+				delay = d
+				if delay > cfg.MaxRetryAfter {
+					delay = cfg.MaxRetryAfter
+				}
+			}
+			io.Copy(ioutil.Discard, res.Body) // errcheck exclude
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func shouldRetry(cfg RetryConfig, method string, res *http.Response, err error) bool {
+	if err != nil {
+		for _, m := range cfg.RetryOnMethods {
+			if strings.EqualFold(method, m) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, code := range cfg.RetryOnStatus {
+		if res.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date.
+//
+func retryAfter(header http.Header) (time.Duration, bool) {
+	v := header.Get(HeaderRetryAfter)
+	if len(v) == 0 {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
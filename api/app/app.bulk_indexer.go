@@ -0,0 +1,308 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxBatchDocuments is the largest number of documents App Search accepts in one request.
+	maxBatchDocuments = 100
+	// maxBatchBytes is the largest payload App Search accepts in one request.
+	maxBatchBytes = 10 * 1024 * 1024
+)
+
+// ErrBulkIndexerClosed is returned by BulkIndexer.Add once the indexer has been closed.
+var ErrBulkIndexerClosed = errors.New("bulk indexer is closed")
+
+// BulkIndexerConfig configures a BulkIndexer.
+type BulkIndexerConfig struct {
+	Client *API
+	Engine string
+	// Auth is an App Search private key authenticating the requests instead of the credentials of the client.
+	Auth string
+
+	NumWorkers     int           // number of workers, defaults to the number of CPUs
+	FlushDocuments int           // documents per request, defaults to and is capped at 100
+	FlushBytes     int           // bytes per request, defaults to and is capped at 10MB
+	FlushInterval  time.Duration // interval of flushing pending documents, defaults to 30s
+
+	// OnError is called when a whole request fails.
+	OnError func(context.Context, error)
+}
+
+// BulkIndexerItem is a single document to index.
+type BulkIndexerItem struct {
+	Document map[string]interface{}
+
+	// OnSuccess is called when the document is indexed.
+	OnSuccess func(context.Context, BulkIndexerItem, DocumentResult)
+	// OnFailure is called when the document is rejected, err is nil when the
+	// failure is reported by App Search in the Errors of the result.
+	OnFailure func(context.Context, BulkIndexerItem, DocumentResult, error)
+}
+
+// BulkIndexerStats holds the counters of a BulkIndexer.
+type BulkIndexerStats struct {
+	NumAdded    uint64
+	NumFlushed  uint64
+	NumFailed   uint64
+	NumIndexed  uint64
+	NumRequests uint64
+}
+
+// BulkIndexer indexes documents added one at a time, batching them by count
+// and size and sending the batches from several workers.
+//
+//	bi, err := app.NewBulkIndexer(app.BulkIndexerConfig{
+//		Client: client.AppSearch.API,
+//		Engine: engine,
+//	})
+//	for _, doc := range docs {
+//		if err := bi.Add(ctx, app.BulkIndexerItem{Document: doc}); err != nil {
+//		}
+//	}
+//	if err := bi.Close(ctx); err != nil {
+//	}
+//	stats := bi.Stats()
+type BulkIndexer struct {
+	config BulkIndexerConfig
+	queue  chan bulkIndexerItem
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	closed bool
+	adding sync.WaitGroup
+
+	stats BulkIndexerStats
+}
+
+type bulkIndexerItem struct {
+	BulkIndexerItem
+	body json.RawMessage
+}
+
+// NewBulkIndexer creates a BulkIndexer and starts its workers.
+func NewBulkIndexer(cfg BulkIndexerConfig) (*BulkIndexer, error) {
+	if cfg.Client == nil {
+		return nil, errors.New("cannot create bulk indexer: client is not set")
+	}
+	if len(cfg.Engine) == 0 {
+		return nil, errors.New("cannot create bulk indexer: engine is not set")
+	}
+
+	if cfg.NumWorkers <= 0 {
+		cfg.NumWorkers = runtime.NumCPU()
+	}
+	if cfg.FlushDocuments <= 0 || cfg.FlushDocuments > maxBatchDocuments {
+		cfg.FlushDocuments = maxBatchDocuments
+	}
+	if cfg.FlushBytes <= 0 || cfg.FlushBytes > maxBatchBytes {
+		cfg.FlushBytes = maxBatchBytes
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bi := &BulkIndexer{
+		config: cfg,
+		queue:  make(chan bulkIndexerItem, cfg.NumWorkers),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	bi.wg.Add(cfg.NumWorkers)
+	for i := 0; i < cfg.NumWorkers; i++ {
+		go bi.work()
+	}
+
+	return bi, nil
+}
+
+// Add queues a document for indexing, it blocks until a worker accepts the document or ctx is done.
+// A document which cannot fit in a request of FlushBytes is rejected.
+func (bi *BulkIndexer) Add(ctx context.Context, item BulkIndexerItem) error {
+	body, err := json.Marshal(item.Document)
+	if err != nil {
+		return fmt.Errorf("cannot encode document: %s", err)
+	}
+
+	// the array brackets take two bytes
+	if len(body)+2 > bi.config.FlushBytes {
+		return fmt.Errorf("document of %d bytes exceeds the limit of %d bytes", len(body), bi.config.FlushBytes)
+	}
+
+	bi.mu.Lock()
+	if bi.closed {
+		bi.mu.Unlock()
+		return ErrBulkIndexerClosed
+	}
+	bi.adding.Add(1)
+	bi.mu.Unlock()
+	defer bi.adding.Done()
+
+	select {
+	case bi.queue <- bulkIndexerItem{BulkIndexerItem: item, body: body}:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-bi.ctx.Done():
+		return ErrBulkIndexerClosed
+	}
+
+	atomic.AddUint64(&bi.stats.NumAdded, 1)
+	return nil
+}
+
+// Close flushes the pending documents and stops the workers. When ctx is done
+// before the workers finish, requests in flight are cancelled and ctx.Err() is returned.
+func (bi *BulkIndexer) Close(ctx context.Context) error {
+	bi.mu.Lock()
+	closing := !bi.closed
+	bi.closed = true
+	bi.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		if closing {
+			// documents being added are still accepted by the workers
+			bi.adding.Wait()
+			close(bi.queue)
+		}
+		bi.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		bi.cancel()
+		return nil
+	case <-ctx.Done():
+		bi.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Stats returns the counters of the indexer.
+func (bi *BulkIndexer) Stats() BulkIndexerStats {
+	return BulkIndexerStats{
+		NumAdded:    atomic.LoadUint64(&bi.stats.NumAdded),
+		NumFlushed:  atomic.LoadUint64(&bi.stats.NumFlushed),
+		NumFailed:   atomic.LoadUint64(&bi.stats.NumFailed),
+		NumIndexed:  atomic.LoadUint64(&bi.stats.NumIndexed),
+		NumRequests: atomic.LoadUint64(&bi.stats.NumRequests),
+	}
+}
+
+func (bi *BulkIndexer) work() {
+	defer bi.wg.Done()
+
+	ticker := time.NewTicker(bi.config.FlushInterval)
+	defer ticker.Stop()
+
+	var (
+		items []bulkIndexerItem
+		buf   bytes.Buffer
+	)
+
+	flush := func() {
+		if len(items) > 0 {
+			buf.WriteByte(']')
+			bi.flush(items, buf.Bytes())
+		}
+		items = items[:0]
+		buf.Reset()
+	}
+
+	for {
+		select {
+		case item, ok := <-bi.queue:
+			if !ok {
+				flush()
+				return
+			}
+
+			// the array brackets and a separator take one byte each
+			if len(items) > 0 && buf.Len()+len(item.body)+2 > bi.config.FlushBytes {
+				flush()
+			}
+
+			if len(items) == 0 {
+				buf.WriteByte('[')
+			} else {
+				buf.WriteByte(',')
+			}
+			buf.Write(item.body)
+			items = append(items, item)
+
+			if len(items) >= bi.config.FlushDocuments || buf.Len()+1 >= bi.config.FlushBytes {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (bi *BulkIndexer) flush(items []bulkIndexerItem, body []byte) {
+	ctx := bi.ctx
+
+	atomic.AddUint64(&bi.stats.NumRequests, 1)
+
+	results, err := bi.send(ctx, body)
+	if err == nil && len(results) != len(items) {
+		err = fmt.Errorf("expect to get %d results, but got %d", len(items), len(results))
+	}
+	if err != nil {
+		if bi.config.OnError != nil {
+			bi.config.OnError(ctx, err)
+		}
+		atomic.AddUint64(&bi.stats.NumFailed, uint64(len(items)))
+		for _, item := range items {
+			if item.OnFailure != nil {
+				item.OnFailure(ctx, item.BulkIndexerItem, DocumentResult{}, err)
+			}
+		}
+		return
+	}
+
+	atomic.AddUint64(&bi.stats.NumFlushed, uint64(len(items)))
+	for i, item := range items {
+		result := results[i]
+		if len(result.Errors) > 0 {
+			atomic.AddUint64(&bi.stats.NumFailed, 1)
+			if item.OnFailure != nil {
+				item.OnFailure(ctx, item.BulkIndexerItem, result, nil)
+			}
+			continue
+		}
+		atomic.AddUint64(&bi.stats.NumIndexed, 1)
+		if item.OnSuccess != nil {
+			item.OnSuccess(ctx, item.BulkIndexerItem, result)
+		}
+	}
+}
+
+func (bi *BulkIndexer) send(ctx context.Context, body []byte) ([]DocumentResult, error) {
+	create := bi.config.Client.Documents.Create
+	res, err := create(
+		bi.config.Engine,
+		create.WithContext(ctx),
+		create.WithAuth(bi.config.Auth),
+		create.WithBody(bytes.NewReader(body)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeDocumentsResponse(res)
+}
@@ -0,0 +1,453 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/nevill/jiangjing/api"
+)
+
+// KeyType is the type of an App Search API key.
+type KeyType string
+
+const (
+	KeyTypePrivate KeyType = "private"
+	KeyTypeSearch  KeyType = "search"
+	KeyTypeAdmin   KeyType = "admin"
+)
+
+// Credential describes an App Search API key.
+// Read and Write only apply to private keys, Engines is ignored when AccessAllEngines is set.
+type Credential struct {
+	ID               string   `json:"id,omitempty"`
+	Name             string   `json:"name"`
+	Type             KeyType  `json:"type"`
+	Key              string   `json:"key,omitempty"`
+	Read             bool     `json:"read"`
+	Write            bool     `json:"write"`
+	AccessAllEngines bool     `json:"access_all_engines"`
+	Engines          []string `json:"engines,omitempty"`
+}
+
+type Credentials struct {
+	List   CredentialsList
+	Get    CredentialsGet
+	Create CredentialsCreate
+	Update CredentialsUpdate
+	Delete CredentialsDelete
+}
+
+// CredentialsList retrieves all API keys.
+// see https://www.elastic.co/guide/en/app-search/current/credentials.html#credentials-all for details.
+type CredentialsList func(o ...func(*CredentialsListRequest)) (*api.Response, error)
+
+func (h CredentialsList) WithContext(ctx context.Context) func(*CredentialsListRequest) {
+	return func(r *CredentialsListRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h CredentialsList) WithAuth(apiKey string) func(*CredentialsListRequest) {
+	return func(r *CredentialsListRequest) {
+		r.Auth = apiKey
+	}
+}
+
+// WithPage sets the page number and the number of keys per page.
+func (h CredentialsList) WithPage(current, size int) func(*CredentialsListRequest) {
+	return func(r *CredentialsListRequest) {
+		r.Current = current
+		r.Size = size
+	}
+}
+
+// All returns a ListIterator over API keys of all pages, each item decodes into a Credential.
+func (h CredentialsList) All(o ...func(*CredentialsListRequest)) *ListIterator {
+	return newListIterator(func(page int) (*api.Response, error) {
+		return h(append(o[:len(o):len(o)], func(r *CredentialsListRequest) {
+			r.Current = page
+		})...)
+	})
+}
+
+func newCredentialsListFunc(tp api.Transport) CredentialsList {
+	return func(o ...func(*CredentialsListRequest)) (*api.Response, error) {
+		r := CredentialsListRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type CredentialsListRequest struct {
+	api.Request
+	Current int
+	Size    int
+}
+
+func (r CredentialsListRequest) Do() (*api.Response, error) {
+	path := "/api/as/v1/credentials"
+
+	path += pageParams(r.Current, r.Size)
+
+	req, err := api.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// CredentialsGet retrieves an API key by its name.
+// see https://www.elastic.co/guide/en/app-search/current/credentials.html#credentials-single for details.
+type CredentialsGet func(name string, o ...func(*CredentialsGetRequest)) (*api.Response, error)
+
+func (h CredentialsGet) WithContext(ctx context.Context) func(*CredentialsGetRequest) {
+	return func(r *CredentialsGetRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h CredentialsGet) WithAuth(apiKey string) func(*CredentialsGetRequest) {
+	return func(r *CredentialsGetRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newCredentialsGetFunc(tp api.Transport) CredentialsGet {
+	return func(name string, o ...func(*CredentialsGetRequest)) (*api.Response, error) {
+		r := CredentialsGetRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Name: name,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type CredentialsGetRequest struct {
+	api.Request
+	Name string
+}
+
+func (r CredentialsGetRequest) Do() (*api.Response, error) {
+	path := api.Path("/api/as/v1/credentials", r.Name)
+	req, err := api.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// CredentialsCreate creates a new API key.
+// see https://www.elastic.co/guide/en/app-search/current/credentials.html#credentials-create for details.
+type CredentialsCreate func(key Credential, o ...func(*CredentialsCreateRequest)) (*api.Response, error)
+
+func (h CredentialsCreate) WithContext(ctx context.Context) func(*CredentialsCreateRequest) {
+	return func(r *CredentialsCreateRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h CredentialsCreate) WithAuth(apiKey string) func(*CredentialsCreateRequest) {
+	return func(r *CredentialsCreateRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newCredentialsCreateFunc(tp api.Transport) CredentialsCreate {
+	return func(key Credential, o ...func(*CredentialsCreateRequest)) (*api.Response, error) {
+		r := CredentialsCreateRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Key: key,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type CredentialsCreateRequest struct {
+	api.Request
+	Key Credential
+}
+
+func (r CredentialsCreateRequest) Do() (*api.Response, error) {
+	path := "/api/as/v1/credentials"
+
+	params, err := credentialBody(r.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := api.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	if len(body) > 0 {
+		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// CredentialsUpdate updates an API key by its name, the key itself is kept.
+// see https://www.elastic.co/guide/en/app-search/current/credentials.html#credentials-update for details.
+type CredentialsUpdate func(name string, key Credential, o ...func(*CredentialsUpdateRequest)) (*api.Response, error)
+
+func (h CredentialsUpdate) WithContext(ctx context.Context) func(*CredentialsUpdateRequest) {
+	return func(r *CredentialsUpdateRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h CredentialsUpdate) WithAuth(apiKey string) func(*CredentialsUpdateRequest) {
+	return func(r *CredentialsUpdateRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newCredentialsUpdateFunc(tp api.Transport) CredentialsUpdate {
+	return func(name string, key Credential, o ...func(*CredentialsUpdateRequest)) (*api.Response, error) {
+		r := CredentialsUpdateRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Name: name,
+			Key:  key,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type CredentialsUpdateRequest struct {
+	api.Request
+	Name string
+	Key  Credential
+}
+
+func (r CredentialsUpdateRequest) Do() (*api.Response, error) {
+	path := api.Path("/api/as/v1/credentials", r.Name)
+
+	key := r.Key
+	if len(key.Name) == 0 {
+		key.Name = r.Name
+	}
+
+	params, err := credentialBody(key)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := api.NewRequest(http.MethodPut, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	if len(body) > 0 {
+		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// CredentialsDelete deletes an API key by its name.
+// see https://www.elastic.co/guide/en/app-search/current/credentials.html#credentials-destroy for details.
+type CredentialsDelete func(name string, o ...func(*CredentialsDeleteRequest)) (*api.Response, error)
+
+func (h CredentialsDelete) WithContext(ctx context.Context) func(*CredentialsDeleteRequest) {
+	return func(r *CredentialsDeleteRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h CredentialsDelete) WithAuth(apiKey string) func(*CredentialsDeleteRequest) {
+	return func(r *CredentialsDeleteRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newCredentialsDeleteFunc(tp api.Transport) CredentialsDelete {
+	return func(name string, o ...func(*CredentialsDeleteRequest)) (*api.Response, error) {
+		r := CredentialsDeleteRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Name: name,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type CredentialsDeleteRequest struct {
+	api.Request
+	Name string
+}
+
+func (r CredentialsDeleteRequest) Do() (*api.Response, error) {
+	path := api.Path("/api/as/v1/credentials", r.Name)
+	req, err := api.NewRequest(http.MethodDelete, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// credentialBody builds the request body for the given key,
+// only the properties accepted by its type are included.
+func credentialBody(key Credential) (map[string]interface{}, error) {
+	body := map[string]interface{}{
+		"name": key.Name,
+		"type": key.Type,
+	}
+
+	switch key.Type {
+	case KeyTypePrivate:
+		body["read"] = key.Read
+		body["write"] = key.Write
+	case KeyTypeSearch:
+	case KeyTypeAdmin:
+		return body, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", key.Type)
+	}
+
+	body["access_all_engines"] = key.AccessAllEngines
+	if !key.AccessAllEngines {
+		engines := key.Engines
+		if engines == nil {
+			engines = []string{}
+		}
+		body["engines"] = engines
+	}
+	return body, nil
+}
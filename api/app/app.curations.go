@@ -0,0 +1,455 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/nevill/jiangjing/api"
+)
+
+type Curations struct {
+	List   CurationsList
+	Get    CurationsGet
+	Create CurationsCreate
+	Update CurationsUpdate
+	Delete CurationsDelete
+}
+
+// CurationsList retrieves available curations for the engine.
+// see https://www.elastic.co/guide/en/app-search/current/curations.html#curations-read for details.
+type CurationsList func(name string, o ...func(*CurationsListRequest)) (*api.Response, error)
+
+func (h CurationsList) WithContext(ctx context.Context) func(*CurationsListRequest) {
+	return func(r *CurationsListRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h CurationsList) WithAuth(apiKey string) func(*CurationsListRequest) {
+	return func(r *CurationsListRequest) {
+		r.Auth = apiKey
+	}
+}
+
+// WithPage sets the page number and the number of curations per page.
+func (h CurationsList) WithPage(current, size int) func(*CurationsListRequest) {
+	return func(r *CurationsListRequest) {
+		r.Current = current
+		r.Size = size
+	}
+}
+
+// All returns a ListIterator over curations of all pages.
+func (h CurationsList) All(name string, o ...func(*CurationsListRequest)) *ListIterator {
+	return newListIterator(func(page int) (*api.Response, error) {
+		return h(name, append(o[:len(o):len(o)], func(r *CurationsListRequest) {
+			r.Current = page
+		})...)
+	})
+}
+
+func newCurationsListFunc(tp api.Transport) CurationsList {
+	return func(name string, o ...func(*CurationsListRequest)) (*api.Response, error) {
+		r := CurationsListRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine: name,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type CurationsListRequest struct {
+	api.Request
+	Engine  string
+	Current int
+	Size    int
+}
+
+func (r CurationsListRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "curations")
+	if err != nil {
+		return nil, err
+	}
+
+	path += pageParams(r.Current, r.Size)
+
+	req, err := api.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// CurationsGet retrieves a curation by ID.
+// see https://www.elastic.co/guide/en/app-search/current/curations.html#curations-read for details.
+type CurationsGet func(name, id string, o ...func(*CurationsGetRequest)) (*api.Response, error)
+
+func (h CurationsGet) WithContext(ctx context.Context) func(*CurationsGetRequest) {
+	return func(r *CurationsGetRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h CurationsGet) WithAuth(apiKey string) func(*CurationsGetRequest) {
+	return func(r *CurationsGetRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newCurationsGetFunc(tp api.Transport) CurationsGet {
+	return func(name, id string, o ...func(*CurationsGetRequest)) (*api.Response, error) {
+		r := CurationsGetRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine: name,
+			Id:     id,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type CurationsGetRequest struct {
+	api.Request
+	Engine string
+	Id     string
+}
+
+func (r CurationsGetRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "curations", r.Id)
+	if err != nil {
+		return nil, err
+	}
+	req, err := api.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// CurationsCreate creates a new curation for the given queries.
+// see https://www.elastic.co/guide/en/app-search/current/curations.html#curations-create for details.
+type CurationsCreate func(name string, queries []string, o ...func(*CurationsCreateRequest)) (*api.Response, error)
+
+func (h CurationsCreate) WithContext(ctx context.Context) func(*CurationsCreateRequest) {
+	return func(r *CurationsCreateRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h CurationsCreate) WithAuth(apiKey string) func(*CurationsCreateRequest) {
+	return func(r *CurationsCreateRequest) {
+		r.Auth = apiKey
+	}
+}
+
+// WithPromoted sets the IDs of documents to be promoted to the top of the results.
+func (h CurationsCreate) WithPromoted(ids ...string) func(*CurationsCreateRequest) {
+	return func(r *CurationsCreateRequest) {
+		r.Promoted = append(r.Promoted, ids...)
+	}
+}
+
+// WithHidden sets the IDs of documents to be excluded from the results.
+func (h CurationsCreate) WithHidden(ids ...string) func(*CurationsCreateRequest) {
+	return func(r *CurationsCreateRequest) {
+		r.Hidden = append(r.Hidden, ids...)
+	}
+}
+
+func newCurationsCreateFunc(tp api.Transport) CurationsCreate {
+	return func(name string, queries []string, o ...func(*CurationsCreateRequest)) (*api.Response, error) {
+		r := CurationsCreateRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine:  name,
+			Queries: queries,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type CurationsCreateRequest struct {
+	api.Request
+	Engine   string
+	Queries  []string
+	Promoted []string
+	Hidden   []string
+}
+
+func (r CurationsCreateRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "curations")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(curationBody(r.Queries, r.Promoted, r.Hidden))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := api.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	if len(body) > 0 {
+		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// CurationsUpdate updates a curation by ID, promoted and hidden documents are replaced entirely.
+// see https://www.elastic.co/guide/en/app-search/current/curations.html#curations-update for details.
+type CurationsUpdate func(name, id string, queries []string, o ...func(*CurationsUpdateRequest)) (*api.Response, error)
+
+func (h CurationsUpdate) WithContext(ctx context.Context) func(*CurationsUpdateRequest) {
+	return func(r *CurationsUpdateRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h CurationsUpdate) WithAuth(apiKey string) func(*CurationsUpdateRequest) {
+	return func(r *CurationsUpdateRequest) {
+		r.Auth = apiKey
+	}
+}
+
+// WithPromoted sets the IDs of documents to be promoted to the top of the results.
+func (h CurationsUpdate) WithPromoted(ids ...string) func(*CurationsUpdateRequest) {
+	return func(r *CurationsUpdateRequest) {
+		r.Promoted = append(r.Promoted, ids...)
+	}
+}
+
+// WithHidden sets the IDs of documents to be excluded from the results.
+func (h CurationsUpdate) WithHidden(ids ...string) func(*CurationsUpdateRequest) {
+	return func(r *CurationsUpdateRequest) {
+		r.Hidden = append(r.Hidden, ids...)
+	}
+}
+
+func newCurationsUpdateFunc(tp api.Transport) CurationsUpdate {
+	return func(name, id string, queries []string, o ...func(*CurationsUpdateRequest)) (*api.Response, error) {
+		r := CurationsUpdateRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine:  name,
+			Id:      id,
+			Queries: queries,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type CurationsUpdateRequest struct {
+	api.Request
+	Engine   string
+	Id       string
+	Queries  []string
+	Promoted []string
+	Hidden   []string
+}
+
+func (r CurationsUpdateRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "curations", r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(curationBody(r.Queries, r.Promoted, r.Hidden))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := api.NewRequest(http.MethodPut, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	if len(body) > 0 {
+		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// CurationsDelete deletes a curation by ID.
+// see https://www.elastic.co/guide/en/app-search/current/curations.html#curations-destroy for details.
+type CurationsDelete func(name, id string, o ...func(*CurationsDeleteRequest)) (*api.Response, error)
+
+func (h CurationsDelete) WithContext(ctx context.Context) func(*CurationsDeleteRequest) {
+	return func(r *CurationsDeleteRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h CurationsDelete) WithAuth(apiKey string) func(*CurationsDeleteRequest) {
+	return func(r *CurationsDeleteRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newCurationsDeleteFunc(tp api.Transport) CurationsDelete {
+	return func(name, id string, o ...func(*CurationsDeleteRequest)) (*api.Response, error) {
+		r := CurationsDeleteRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine: name,
+			Id:     id,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type CurationsDeleteRequest struct {
+	api.Request
+	Engine string
+	Id     string
+}
+
+func (r CurationsDeleteRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "curations", r.Id)
+	if err != nil {
+		return nil, err
+	}
+	req, err := api.NewRequest(http.MethodDelete, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+func curationBody(queries, promoted, hidden []string) map[string]interface{} {
+	body := map[string]interface{}{
+		"queries": queries,
+	}
+	if len(promoted) > 0 {
+		body["promoted"] = promoted
+	}
+	if len(hidden) > 0 {
+		body["hidden"] = hidden
+	}
+	return body
+}
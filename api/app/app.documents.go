@@ -5,17 +5,107 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/nevill/jiangjing/api"
 )
 
 type Documents struct {
+	Get    DocumentsGet
 	Create DocumentsCreate
+	Update DocumentsUpdate
 	Delete DocumentsDelete
 	List   DocumentsList
 }
 
+// DocumentsGet retrieves one or more documents by ID.
+// see https://www.elastic.co/guide/en/app-search/current/documents.html#documents-get for details.
+type DocumentsGet func(string, ...func(*DocumentsGetRequest)) (*api.Response, error)
+
+func (DocumentsGet) WithContext(ctx context.Context) func(*DocumentsGetRequest) {
+	return func(r *DocumentsGetRequest) {
+		r.Context = ctx
+	}
+}
+
+func (DocumentsGet) WithAuth(apiKey string) func(*DocumentsGetRequest) {
+	return func(r *DocumentsGetRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func (DocumentsGet) WithIds(ids ...string) func(*DocumentsGetRequest) {
+	return func(r *DocumentsGetRequest) {
+		r.Ids = append(r.Ids, ids...)
+	}
+}
+
+func newDocumentsGetFunc(tp api.Transport) DocumentsGet {
+	return func(engine string, o ...func(*DocumentsGetRequest)) (*api.Response, error) {
+		r := DocumentsGetRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine: engine,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type DocumentsGetRequest struct {
+	api.Request
+	Engine string
+	Ids    []string
+}
+
+func (r DocumentsGetRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "documents")
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	for _, id := range r.Ids {
+		params.Add("ids[]", id)
+	}
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	req, err := api.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
 type DocumentsCreate func(string, ...func(*DocumentsCreateRequest)) (*api.Response, error)
 
 func (DocumentsCreate) WithContext(ctx context.Context) func(*DocumentsCreateRequest) {
@@ -24,12 +114,33 @@ func (DocumentsCreate) WithContext(ctx context.Context) func(*DocumentsCreateReq
 	}
 }
 
+func (DocumentsCreate) WithAuth(apiKey string) func(*DocumentsCreateRequest) {
+	return func(r *DocumentsCreateRequest) {
+		r.Auth = apiKey
+	}
+}
+
 func (DocumentsCreate) WithDocuments(docs ...map[string]interface{}) func(*DocumentsCreateRequest) {
 	return func(r *DocumentsCreateRequest) {
 		r.Documents = append(r.Documents, docs...)
 	}
 }
 
+// WithFailOnPartial makes the request return a *DocumentsError along with the response
+// when some of the documents are rejected, the body of the response can still be read.
+func (DocumentsCreate) WithFailOnPartial() func(*DocumentsCreateRequest) {
+	return func(r *DocumentsCreateRequest) {
+		r.FailOnPartial = true
+	}
+}
+
+// WithBody sets the JSON array of documents as the body of the request, it takes precedence over WithDocuments.
+func (DocumentsCreate) WithBody(body io.Reader) func(*DocumentsCreateRequest) {
+	return func(r *DocumentsCreateRequest) {
+		r.Body = body
+	}
+}
+
 func newDocumentsCreateFunc(tp api.Transport) DocumentsCreate {
 	return func(engine string, o ...func(*DocumentsCreateRequest)) (*api.Response, error) {
 		r := DocumentsCreateRequest{
@@ -46,20 +157,122 @@ func newDocumentsCreateFunc(tp api.Transport) DocumentsCreate {
 }
 
 type DocumentsCreateRequest struct {
+	api.Request
+	Engine        string
+	Documents     []map[string]interface{}
+	Body          io.Reader
+	FailOnPartial bool
+}
+
+func (r DocumentsCreateRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "documents")
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Body == nil {
+		body, err := json.Marshal(r.Documents)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = bytes.NewReader(body)
+	}
+
+	req, err := api.NewRequest(http.MethodPost, path, r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	if r.FailOnPartial && !response.IsError() {
+		var results []DocumentResult
+		if err := peekDocumentResults(&response, &results); err != nil {
+			return &response, err
+		}
+		if err := newDocumentsError(results); err != nil {
+			return &response, err
+		}
+	}
+
+	return &response, nil
+}
+
+// DocumentsUpdate partially updates existing documents, each document must contain an id field.
+// The response contains the result of every document in the same order as they were sent.
+// see https://www.elastic.co/guide/en/app-search/current/documents.html#documents-partial for details.
+type DocumentsUpdate func(string, ...func(*DocumentsUpdateRequest)) (*api.Response, error)
+
+func (DocumentsUpdate) WithContext(ctx context.Context) func(*DocumentsUpdateRequest) {
+	return func(r *DocumentsUpdateRequest) {
+		r.Context = ctx
+	}
+}
+
+func (DocumentsUpdate) WithAuth(apiKey string) func(*DocumentsUpdateRequest) {
+	return func(r *DocumentsUpdateRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func (DocumentsUpdate) WithDocuments(docs ...map[string]interface{}) func(*DocumentsUpdateRequest) {
+	return func(r *DocumentsUpdateRequest) {
+		r.Documents = append(r.Documents, docs...)
+	}
+}
+
+func newDocumentsUpdateFunc(tp api.Transport) DocumentsUpdate {
+	return func(engine string, o ...func(*DocumentsUpdateRequest)) (*api.Response, error) {
+		r := DocumentsUpdateRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine: engine,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type DocumentsUpdateRequest struct {
 	api.Request
 	Engine    string
 	Documents []map[string]interface{}
 }
 
-func (r DocumentsCreateRequest) Do() (*api.Response, error) {
-	path := fmt.Sprintf("/api/as/v1/engines/%s/documents", r.Engine)
+func (r DocumentsUpdateRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "documents")
+	if err != nil {
+		return nil, err
+	}
 
 	body, err := json.Marshal(r.Documents)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := api.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req, err := api.NewRequest(http.MethodPatch, path, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -68,6 +281,10 @@ func (r DocumentsCreateRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	if len(body) > 0 {
 		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
 	}
@@ -94,12 +311,26 @@ func (DocumentsDelete) WithContext(ctx context.Context) func(*DocumentsDeleteReq
 	}
 }
 
+func (DocumentsDelete) WithAuth(apiKey string) func(*DocumentsDeleteRequest) {
+	return func(r *DocumentsDeleteRequest) {
+		r.Auth = apiKey
+	}
+}
+
 func (DocumentsDelete) WithIds(ids ...string) func(*DocumentsDeleteRequest) {
 	return func(r *DocumentsDeleteRequest) {
 		r.Ids = append(r.Ids, ids...)
 	}
 }
 
+// WithFailOnPartial makes the request return a *DocumentsError along with the response
+// when some of the documents are not deleted, the body of the response can still be read.
+func (DocumentsDelete) WithFailOnPartial() func(*DocumentsDeleteRequest) {
+	return func(r *DocumentsDeleteRequest) {
+		r.FailOnPartial = true
+	}
+}
+
 func newDocumentsDeleteFunc(tp api.Transport) DocumentsDelete {
 	return func(engine string, o ...func(*DocumentsDeleteRequest)) (*api.Response, error) {
 		r := DocumentsDeleteRequest{
@@ -117,12 +348,16 @@ func newDocumentsDeleteFunc(tp api.Transport) DocumentsDelete {
 
 type DocumentsDeleteRequest struct {
 	api.Request
-	Engine string
-	Ids    []string
+	Engine        string
+	Ids           []string
+	FailOnPartial bool
 }
 
 func (r DocumentsDeleteRequest) Do() (*api.Response, error) {
-	path := fmt.Sprintf("/api/as/v1/engines/%s/documents", r.Engine)
+	path, err := enginePath(r.Engine, "documents")
+	if err != nil {
+		return nil, err
+	}
 
 	body, err := json.Marshal(r.Ids)
 	if err != nil {
@@ -138,6 +373,10 @@ func (r DocumentsDeleteRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	if len(body) > 0 {
 		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
 	}
@@ -153,6 +392,22 @@ func (r DocumentsDeleteRequest) Do() (*api.Response, error) {
 		Header:     res.Header,
 	}
 
+	if r.FailOnPartial && !response.IsError() {
+		var deleted []DocumentDeleteResult
+		if err := peekDocumentResults(&response, &deleted); err != nil {
+			return &response, err
+		}
+		var results []DocumentResult
+		for _, d := range deleted {
+			if !d.Deleted {
+				results = append(results, DocumentResult{ID: d.ID, Errors: []string{"document was not deleted"}})
+			}
+		}
+		if err := newDocumentsError(results); err != nil {
+			return &response, err
+		}
+	}
+
 	return &response, nil
 }
 
@@ -164,6 +419,29 @@ func (DocumentsList) WithContext(ctx context.Context) func(*DocumentsListRequest
 	}
 }
 
+func (DocumentsList) WithAuth(apiKey string) func(*DocumentsListRequest) {
+	return func(r *DocumentsListRequest) {
+		r.Auth = apiKey
+	}
+}
+
+// WithPage sets the page number and the number of documents per page.
+func (DocumentsList) WithPage(current, size int) func(*DocumentsListRequest) {
+	return func(r *DocumentsListRequest) {
+		r.Current = current
+		r.Size = size
+	}
+}
+
+// All returns a ListIterator over documents of all pages.
+func (h DocumentsList) All(engine string, o ...func(*DocumentsListRequest)) *ListIterator {
+	return newListIterator(func(page int) (*api.Response, error) {
+		return h(engine, append(o[:len(o):len(o)], func(r *DocumentsListRequest) {
+			r.Current = page
+		})...)
+	})
+}
+
 func newDocumentsListFunc(tp api.Transport) DocumentsList {
 	return func(engine string, o ...func(*DocumentsListRequest)) (*api.Response, error) {
 		r := DocumentsListRequest{
@@ -181,11 +459,17 @@ func newDocumentsListFunc(tp api.Transport) DocumentsList {
 
 type DocumentsListRequest struct {
 	api.Request
-	Engine string
+	Engine  string
+	Current int
+	Size    int
 }
 
 func (r DocumentsListRequest) Do() (*api.Response, error) {
-	path := fmt.Sprintf("/api/as/v1/engines/%s/documents/list", r.Engine)
+	path, err := enginePath(r.Engine, "documents", "list")
+	if err != nil {
+		return nil, err
+	}
+	path += pageParams(r.Current, r.Size)
 	req, err := api.NewRequest(http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
@@ -195,6 +479,10 @@ func (r DocumentsListRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	res, err := r.Transport.Perform(req)
 	if err != nil {
 		return nil, err
@@ -208,3 +496,92 @@ func (r DocumentsListRequest) Do() (*api.Response, error) {
 
 	return &response, nil
 }
+
+// DocumentResult is the result of a single document of Create or Update.
+type DocumentResult struct {
+	ID     string   `json:"id"`
+	Errors []string `json:"errors"`
+}
+
+// DocumentDeleteResult is the result of a single document of Delete.
+type DocumentDeleteResult struct {
+	ID      string `json:"id"`
+	Deleted bool   `json:"deleted"`
+}
+
+// DocumentsError is returned when some documents of a request are rejected
+// while the request itself succeeds.
+type DocumentsError struct {
+	Failed []DocumentResult
+}
+
+func (e *DocumentsError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d documents failed:", len(e.Failed))
+	for i, r := range e.Failed {
+		if i > 0 {
+			b.WriteString(";")
+		}
+		fmt.Fprintf(&b, " %s: %s", r.ID, strings.Join(r.Errors, ", "))
+	}
+	return b.String()
+}
+
+// newDocumentsError returns a *DocumentsError of the results having errors, or nil if there is none.
+func newDocumentsError(results []DocumentResult) error {
+	var failed []DocumentResult
+	for _, r := range results {
+		if len(r.Errors) > 0 {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &DocumentsError{Failed: failed}
+}
+
+// peekDocumentResults decodes the body of the response into v and leaves the body readable.
+func peekDocumentResults(res *api.Response, v interface{}) error {
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("error parsing the response body: %s", err)
+	}
+	return nil
+}
+
+// DecodeDocumentsResponse reads the response of Create or Update and closes its body.
+func DecodeDocumentsResponse(res *api.Response) ([]DocumentResult, error) {
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, api.NewError(res)
+	}
+
+	var results []DocumentResult
+	if err := json.NewDecoder(res.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("error parsing the response body: %s", err)
+	}
+	return results, nil
+}
+
+// DecodeDocumentsDeleteResponse reads the response of Delete and closes its body.
+func DecodeDocumentsDeleteResponse(res *api.Response) ([]DocumentDeleteResult, error) {
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, api.NewError(res)
+	}
+
+	var results []DocumentDeleteResult
+	if err := json.NewDecoder(res.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("error parsing the response body: %s", err)
+	}
+	return results, nil
+}
@@ -6,15 +6,57 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/nevill/jiangjing/api"
 )
 
+// EngineType is the type of an engine, a meta engine searches across its source engines.
+type EngineType string
+
+const (
+	EngineTypeDefault EngineType = "default"
+	EngineTypeMeta    EngineType = "meta"
+)
+
+// Languages supported by App Search engines, an empty language stands for Universal.
+// see https://www.elastic.co/guide/en/app-search/current/api-reference.html#overview-api-references-language-support for details.
+var Languages = []string{
+	"da", "de", "en", "es", "fr", "it", "ja", "ko", "nl", "pt", "pt-br", "ru", "th", "zh",
+}
+
+// Engine describes an engine as returned by the engines API.
+type Engine struct {
+	Name          string     `json:"name"`
+	Type          EngineType `json:"type"`
+	Language      *string    `json:"language"`
+	DocumentCount int        `json:"document_count"`
+	SourceEngines []string   `json:"source_engines,omitempty"`
+	IndexName     string     `json:"elasticsearch_index_name,omitempty"`
+}
+
+// DecodeEngine reads an engine from the response of EnginesGet or EnginesCreate and closes its body.
+func DecodeEngine(res *api.Response) (*Engine, error) {
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, api.NewError(res)
+	}
+
+	var engine Engine
+	if err := json.NewDecoder(res.Body).Decode(&engine); err != nil {
+		return nil, fmt.Errorf("error parsing the response body: %s", err)
+	}
+	return &engine, nil
+}
+
 type Engines struct {
-	List   EnginesList
-	Get    EnginesGet
-	Create EnginesCreate
-	Delete EnginesDelete
+	List                EnginesList
+	Get                 EnginesGet
+	Create              EnginesCreate
+	Delete              EnginesDelete
+	AddSourceEngines    EnginesAddSourceEngines
+	RemoveSourceEngines EnginesRemoveSourceEngines
 }
 
 // EnginesList Retrieve all engines.
@@ -27,10 +69,33 @@ func (h EnginesList) WithContext(ctx context.Context) func(*EnginesListRequest)
 	}
 }
 
+func (h EnginesList) WithAuth(apiKey string) func(*EnginesListRequest) {
+	return func(r *EnginesListRequest) {
+		r.Auth = apiKey
+	}
+}
+
+// WithPage sets the page number and the number of engines per page.
+func (h EnginesList) WithPage(current, size int) func(*EnginesListRequest) {
+	return func(r *EnginesListRequest) {
+		r.Current = current
+		r.Size = size
+	}
+}
+
+// All returns a ListIterator over engines of all pages, each item decodes into an Engine.
+func (h EnginesList) All(o ...func(*EnginesListRequest)) *ListIterator {
+	return newListIterator(func(page int) (*api.Response, error) {
+		return h(append(o[:len(o):len(o)], func(r *EnginesListRequest) {
+			r.Current = page
+		})...)
+	})
+}
+
 func newEnginesListFunc(tp api.Transport) EnginesList {
 	return func(o ...func(*EnginesListRequest)) (*api.Response, error) {
 		r := EnginesListRequest{
-			api.Request{
+			Request: api.Request{
 				Transport: tp,
 			},
 		}
@@ -43,10 +108,12 @@ func newEnginesListFunc(tp api.Transport) EnginesList {
 
 type EnginesListRequest struct {
 	api.Request
+	Current int
+	Size    int
 }
 
 func (r EnginesListRequest) Do() (*api.Response, error) {
-	path := "/api/as/v1/engines"
+	path := "/api/as/v1/engines" + pageParams(r.Current, r.Size)
 
 	req, err := api.NewRequest(http.MethodGet, path, nil)
 	if err != nil {
@@ -57,6 +124,10 @@ func (r EnginesListRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	res, err := r.Transport.Perform(req)
 	if err != nil {
 		return nil, err
@@ -81,6 +152,12 @@ func (h EnginesGet) WithContext(ctx context.Context) func(*EnginesGetRequest) {
 	}
 }
 
+func (h EnginesGet) WithAuth(apiKey string) func(*EnginesGetRequest) {
+	return func(r *EnginesGetRequest) {
+		r.Auth = apiKey
+	}
+}
+
 func newEnginesGetFunc(tp api.Transport) EnginesGet {
 	return func(name string, o ...func(*EnginesGetRequest)) (*api.Response, error) {
 		r := EnginesGetRequest{
@@ -102,7 +179,10 @@ type EnginesGetRequest struct {
 }
 
 func (r EnginesGetRequest) Do() (*api.Response, error) {
-	path := fmt.Sprintf("/api/as/v1/engines/%s", r.Name)
+	path, err := enginePath(r.Name)
+	if err != nil {
+		return nil, err
+	}
 
 	req, err := api.NewRequest(http.MethodGet, path, nil)
 	if err != nil {
@@ -113,6 +193,10 @@ func (r EnginesGetRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	res, err := r.Transport.Perform(req)
 	if err != nil {
 		return nil, err
@@ -137,6 +221,49 @@ func (h EnginesCreate) WithContext(ctx context.Context) func(*EnginesCreateReque
 	}
 }
 
+func (h EnginesCreate) WithAuth(apiKey string) func(*EnginesCreateRequest) {
+	return func(r *EnginesCreateRequest) {
+		r.Auth = apiKey
+	}
+}
+
+// WithType sets the type of the engine, use EngineTypeMeta to create a meta engine.
+func (h EnginesCreate) WithType(t EngineType) func(*EnginesCreateRequest) {
+	return func(r *EnginesCreateRequest) {
+		r.Type = t
+	}
+}
+
+// WithSourceEngines sets the source engines of a meta engine.
+func (h EnginesCreate) WithSourceEngines(engines ...string) func(*EnginesCreateRequest) {
+	return func(r *EnginesCreateRequest) {
+		r.SourceEngines = append(r.SourceEngines, engines...)
+	}
+}
+
+// WithLanguage sets the language of the engine, it must be one of Languages.
+func (h EnginesCreate) WithLanguage(language string) func(*EnginesCreateRequest) {
+	return func(r *EnginesCreateRequest) {
+		r.Language = language
+	}
+}
+
+// WithNumberOfShards overrides the number of shards of the underlying index,
+// engines expected to hold tens of millions of documents benefit from more shards.
+func (h EnginesCreate) WithNumberOfShards(shards int) func(*EnginesCreateRequest) {
+	return func(r *EnginesCreateRequest) {
+		r.NumberOfShards = shards
+	}
+}
+
+// WithElasticsearchIndex creates an engine backed by an existing Elasticsearch index.
+// The index name must start with "search-".
+func (h EnginesCreate) WithElasticsearchIndex(index string) func(*EnginesCreateRequest) {
+	return func(r *EnginesCreateRequest) {
+		r.IndexName = index
+	}
+}
+
 func newEngineCreateFunc(tp api.Transport) EnginesCreate {
 	return func(name string, o ...func(*EnginesCreateRequest)) (*api.Response, error) {
 		r := EnginesCreateRequest{
@@ -154,15 +281,57 @@ func newEngineCreateFunc(tp api.Transport) EnginesCreate {
 
 type EnginesCreateRequest struct {
 	api.Request
-	Name string
+	Name           string
+	Type           EngineType
+	SourceEngines  []string
+	Language       string
+	NumberOfShards int
+	IndexName      string
 }
 
 func (r EnginesCreateRequest) Do() (*api.Response, error) {
 	path := "/api/as/v1/engines"
 
-	body, err := json.Marshal(map[string]string{
+	if err := api.ValidateEngineName(r.Name); err != nil {
+		return nil, err
+	}
+	for _, name := range r.SourceEngines {
+		if err := api.ValidateEngineName(name); err != nil {
+			return nil, err
+		}
+	}
+
+	params := map[string]interface{}{
 		"name": r.Name,
-	})
+	}
+	if len(r.Type) > 0 {
+		params["type"] = r.Type
+	}
+	if len(r.SourceEngines) > 0 {
+		params["source_engines"] = r.SourceEngines
+	}
+	if len(r.Language) > 0 {
+		if !isSupportedLanguage(r.Language) {
+			return nil, fmt.Errorf("unsupported language %q, expect one of %s", r.Language, strings.Join(Languages, ", "))
+		}
+		params["language"] = r.Language
+	}
+	if r.NumberOfShards > 0 {
+		params["index_create_settings_override"] = map[string]int{
+			"number_of_shards": r.NumberOfShards,
+		}
+	}
+	if len(r.IndexName) > 0 {
+		if !strings.HasPrefix(r.IndexName, "search-") {
+			return nil, fmt.Errorf("index name %q must start with search-", r.IndexName)
+		}
+		params["search_index"] = map[string]string{
+			"type":       "elasticsearch",
+			"index_name": r.IndexName,
+		}
+	}
+
+	body, err := json.Marshal(params)
 	if err != nil {
 		return nil, err
 	}
@@ -176,6 +345,10 @@ func (r EnginesCreateRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	if len(body) > 0 {
 		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
 	}
@@ -194,7 +367,16 @@ func (r EnginesCreateRequest) Do() (*api.Response, error) {
 	return &response, nil
 }
 
-// EnginesCreate Deletes an engine by name.
+func isSupportedLanguage(language string) bool {
+	for _, l := range Languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
+
+// EnginesDelete Deletes an engine by name.
 // see https://www.elastic.co/guide/en/app-search/current/engines.html#engines-delete for details.
 type EnginesDelete func(name string, o ...func(*EnginesDeleteRequest)) (*api.Response, error)
 
@@ -204,6 +386,12 @@ func (h EnginesDelete) WithContext(ctx context.Context) func(*EnginesDeleteReque
 	}
 }
 
+func (h EnginesDelete) WithAuth(apiKey string) func(*EnginesDeleteRequest) {
+	return func(r *EnginesDeleteRequest) {
+		r.Auth = apiKey
+	}
+}
+
 func newEngineDeleteFunc(tp api.Transport) EnginesDelete {
 	return func(name string, o ...func(*EnginesDeleteRequest)) (*api.Response, error) {
 		r := EnginesDeleteRequest{
@@ -225,7 +413,10 @@ type EnginesDeleteRequest struct {
 }
 
 func (r EnginesDeleteRequest) Do() (*api.Response, error) {
-	path := fmt.Sprintf("/api/as/v1/engines/%s", r.Name)
+	path, err := enginePath(r.Name)
+	if err != nil {
+		return nil, err
+	}
 
 	req, err := api.NewRequest(http.MethodDelete, path, nil)
 	if err != nil {
@@ -236,6 +427,126 @@ func (r EnginesDeleteRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// EnginesAddSourceEngines Adds source engines to an existing meta engine.
+// see https://www.elastic.co/guide/en/app-search/current/meta-engines.html#meta-engines-add-source-engines for details.
+type EnginesAddSourceEngines func(name string, sources []string, o ...func(*EnginesSourceEnginesRequest)) (*api.Response, error)
+
+func (h EnginesAddSourceEngines) WithContext(ctx context.Context) func(*EnginesSourceEnginesRequest) {
+	return func(r *EnginesSourceEnginesRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h EnginesAddSourceEngines) WithAuth(apiKey string) func(*EnginesSourceEnginesRequest) {
+	return func(r *EnginesSourceEnginesRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newEnginesAddSourceEnginesFunc(tp api.Transport) EnginesAddSourceEngines {
+	return func(name string, sources []string, o ...func(*EnginesSourceEnginesRequest)) (*api.Response, error) {
+		r := EnginesSourceEnginesRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Method:        http.MethodPost,
+			Name:          name,
+			SourceEngines: sources,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+// EnginesRemoveSourceEngines Removes source engines from an existing meta engine.
+// see https://www.elastic.co/guide/en/app-search/current/meta-engines.html#meta-engines-remove-source-engines for details.
+type EnginesRemoveSourceEngines func(name string, sources []string, o ...func(*EnginesSourceEnginesRequest)) (*api.Response, error)
+
+func (h EnginesRemoveSourceEngines) WithContext(ctx context.Context) func(*EnginesSourceEnginesRequest) {
+	return func(r *EnginesSourceEnginesRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h EnginesRemoveSourceEngines) WithAuth(apiKey string) func(*EnginesSourceEnginesRequest) {
+	return func(r *EnginesSourceEnginesRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newEnginesRemoveSourceEnginesFunc(tp api.Transport) EnginesRemoveSourceEngines {
+	return func(name string, sources []string, o ...func(*EnginesSourceEnginesRequest)) (*api.Response, error) {
+		r := EnginesSourceEnginesRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Method:        http.MethodDelete,
+			Name:          name,
+			SourceEngines: sources,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+// EnginesSourceEnginesRequest adds (POST) or removes (DELETE) source engines of a meta engine.
+type EnginesSourceEnginesRequest struct {
+	api.Request
+	Method        string
+	Name          string
+	SourceEngines []string
+}
+
+func (r EnginesSourceEnginesRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Name, "source_engines")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(r.SourceEngines)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := api.NewRequest(r.Method, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	if len(body) > 0 {
+		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
+	}
+
 	res, err := r.Transport.Perform(req)
 	if err != nil {
 		return nil, err
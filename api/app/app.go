@@ -7,8 +7,14 @@ import (
 type API struct {
 	Engines   *Engines
 	Synonyms  *Synonyms
+	Curations *Curations
 	Documents *Documents
-	Search    Search
+	Schema    *Schema
+
+	Search         Search
+	MultiSearch    MultiSearch
+	SearchSettings *SearchSettings
+	Credentials    *Credentials
 }
 
 func New(t api.Transport) *API {
@@ -18,6 +24,9 @@ func New(t api.Transport) *API {
 			Get:    newEnginesGetFunc(t),
 			Create: newEngineCreateFunc(t),
 			Delete: newEngineDeleteFunc(t),
+
+			AddSourceEngines:    newEnginesAddSourceEnginesFunc(t),
+			RemoveSourceEngines: newEnginesRemoveSourceEnginesFunc(t),
 		},
 		Synonyms: &Synonyms{
 			List:   newSynonymsListFunc(t),
@@ -26,11 +35,46 @@ func New(t api.Transport) *API {
 			Update: newSynonymsUpdateFunc(t),
 			Delete: newSynonymsDeleteFunc(t),
 		},
+		Curations: &Curations{
+			List:   newCurationsListFunc(t),
+			Get:    newCurationsGetFunc(t),
+			Create: newCurationsCreateFunc(t),
+			Update: newCurationsUpdateFunc(t),
+			Delete: newCurationsDeleteFunc(t),
+		},
 		Documents: &Documents{
+			Get:    newDocumentsGetFunc(t),
 			Create: newDocumentsCreateFunc(t),
+			Update: newDocumentsUpdateFunc(t),
 			Delete: newDocumentsDeleteFunc(t),
 			List:   newDocumentsListFunc(t),
 		},
-		Search: newSearchFunc(t),
+		Schema: &Schema{
+			Get:    newSchemaGetFunc(t),
+			Update: newSchemaUpdateFunc(t),
+		},
+		Search:      newSearchFunc(t),
+		MultiSearch: newMultiSearchFunc(t),
+		SearchSettings: &SearchSettings{
+			Get:    newSearchSettingsGetFunc(t),
+			Update: newSearchSettingsUpdateFunc(t),
+			Reset:  newSearchSettingsResetFunc(t),
+		},
+		Credentials: &Credentials{
+			List:   newCredentialsListFunc(t),
+			Get:    newCredentialsGetFunc(t),
+			Create: newCredentialsCreateFunc(t),
+			Update: newCredentialsUpdateFunc(t),
+			Delete: newCredentialsDeleteFunc(t),
+		},
+	}
+}
+
+// enginePath returns the escaped path of the engine followed by the segments,
+// it fails if the engine name is invalid.
+func enginePath(engine string, segments ...string) (string, error) {
+	if err := api.ValidateEngineName(engine); err != nil {
+		return "", err
 	}
+	return api.Path("/api/as/v1/engines", append([]string{engine}, segments...)...), nil
 }
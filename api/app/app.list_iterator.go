@@ -0,0 +1,107 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/nevill/jiangjing/api"
+)
+
+// ListIterator walks every item of a paginated list, fetching pages until meta.page.total_pages is reached.
+//
+//	it := client.AppSearch.Engines.List.All()
+//	for it.Next() {
+//		var engine app.Engine
+//		if err := it.Decode(&engine); err != nil {
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type ListIterator struct {
+	fetch func(page int) (*api.Response, error)
+
+	page    int
+	items   []json.RawMessage
+	current json.RawMessage
+	done    bool
+	err     error
+}
+
+func newListIterator(fetch func(page int) (*api.Response, error)) *ListIterator {
+	return &ListIterator{fetch: fetch}
+}
+
+// Next advances to the next item, it returns false when there are no more items or an error occurs.
+func (it *ListIterator) Next() bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.err = it.next()
+	}
+
+	it.current, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Item returns the current item undecoded.
+func (it *ListIterator) Item() json.RawMessage {
+	return it.current
+}
+
+// Decode decodes the current item into v.
+func (it *ListIterator) Decode(v interface{}) error {
+	return json.Unmarshal(it.current, v)
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *ListIterator) Err() error {
+	return it.err
+}
+
+func (it *ListIterator) next() error {
+	it.page++
+
+	res, err := it.fetch(it.page)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return api.NewError(res)
+	}
+
+	var r struct {
+		Meta struct {
+			Page PageMeta `json:"page"`
+		} `json:"meta"`
+		Results []json.RawMessage `json:"results"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return fmt.Errorf("error parsing the response body: %s", err)
+	}
+
+	it.items = r.Results
+	if len(r.Results) == 0 || it.page >= r.Meta.Page.TotalPages {
+		it.done = true
+	}
+	return nil
+}
+
+// pageParams returns the query string of the page parameters, or an empty string if none is set.
+func pageParams(current, size int) string {
+	params := url.Values{}
+	if current > 0 {
+		params.Set("page[current]", strconv.Itoa(current))
+	}
+	if size > 0 {
+		params.Set("page[size]", strconv.Itoa(size))
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + params.Encode()
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/nevill/jiangjing/api"
+)
+
+// MultiSearch executes several search queries in one request, results are returned in the same order as the queries.
+// see https://www.elastic.co/guide/en/app-search/current/multi-search.html for details.
+type MultiSearch func(o ...func(*MultiSearchRequest)) (*api.Response, error)
+
+func (MultiSearch) WithContext(ctx context.Context) func(*MultiSearchRequest) {
+	return func(r *MultiSearchRequest) {
+		r.Context = ctx
+	}
+}
+
+func (MultiSearch) WithAuth(apiKey string) func(*MultiSearchRequest) {
+	return func(r *MultiSearchRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func (MultiSearch) WithEngine(engine string) func(*MultiSearchRequest) {
+	return func(r *MultiSearchRequest) {
+		r.Engine = engine
+	}
+}
+
+// WithBodies appends queries, each body is the JSON of a single search query.
+// Queries set by WithBodies are sent before those set by WithQueries.
+func (MultiSearch) WithBodies(bodies ...io.Reader) func(*MultiSearchRequest) {
+	return func(r *MultiSearchRequest) {
+		r.Bodies = append(r.Bodies, bodies...)
+	}
+}
+
+// WithQueries appends typed queries.
+func (MultiSearch) WithQueries(queries ...SearchQuery) func(*MultiSearchRequest) {
+	return func(r *MultiSearchRequest) {
+		r.Queries = append(r.Queries, queries...)
+	}
+}
+
+func newMultiSearchFunc(tp api.Transport) MultiSearch {
+	return func(o ...func(*MultiSearchRequest)) (*api.Response, error) {
+		r := MultiSearchRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type MultiSearchRequest struct {
+	api.Request
+	Engine  string
+	Bodies  []io.Reader
+	Queries []SearchQuery
+}
+
+func (r MultiSearchRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "multi_search")
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]json.RawMessage, 0, len(r.Bodies)+len(r.Queries))
+	for i, b := range r.Bodies {
+		query, err := ioutil.ReadAll(b)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read query %d: %s", i, err)
+		}
+		if !json.Valid(query) {
+			return nil, fmt.Errorf("query %d is not valid JSON", i)
+		}
+		queries = append(queries, query)
+	}
+	for _, q := range r.Queries {
+		query, err := json.Marshal(q)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"queries": queries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := api.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	if len(body) > 0 {
+		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
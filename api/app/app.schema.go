@@ -0,0 +1,183 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/nevill/jiangjing/api"
+)
+
+// FieldType is the type of a field in an engine schema.
+type FieldType string
+
+const (
+	FieldTypeText        FieldType = "text"
+	FieldTypeNumber      FieldType = "number"
+	FieldTypeDate        FieldType = "date"
+	FieldTypeGeolocation FieldType = "geolocation"
+)
+
+type Schema struct {
+	Get    SchemaGet
+	Update SchemaUpdate
+}
+
+// SchemaGet retrieves the schema of the engine.
+// see https://www.elastic.co/guide/en/app-search/current/schema.html#schema-read for details.
+type SchemaGet func(name string, o ...func(*SchemaGetRequest)) (*api.Response, error)
+
+func (h SchemaGet) WithContext(ctx context.Context) func(*SchemaGetRequest) {
+	return func(r *SchemaGetRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h SchemaGet) WithAuth(apiKey string) func(*SchemaGetRequest) {
+	return func(r *SchemaGetRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newSchemaGetFunc(tp api.Transport) SchemaGet {
+	return func(name string, o ...func(*SchemaGetRequest)) (*api.Response, error) {
+		r := SchemaGetRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine: name,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type SchemaGetRequest struct {
+	api.Request
+	Engine string
+}
+
+func (r SchemaGetRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "schema")
+	if err != nil {
+		return nil, err
+	}
+	req, err := api.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// SchemaUpdate creates new fields or changes the type of existing fields.
+// Fields can be added but never removed from a schema.
+// see https://www.elastic.co/guide/en/app-search/current/schema.html#schema-patch for details.
+type SchemaUpdate func(name string, schema map[string]FieldType, o ...func(*SchemaUpdateRequest)) (*api.Response, error)
+
+func (h SchemaUpdate) WithContext(ctx context.Context) func(*SchemaUpdateRequest) {
+	return func(r *SchemaUpdateRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h SchemaUpdate) WithAuth(apiKey string) func(*SchemaUpdateRequest) {
+	return func(r *SchemaUpdateRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newSchemaUpdateFunc(tp api.Transport) SchemaUpdate {
+	return func(name string, schema map[string]FieldType, o ...func(*SchemaUpdateRequest)) (*api.Response, error) {
+		r := SchemaUpdateRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine: name,
+			Schema: schema,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type SchemaUpdateRequest struct {
+	api.Request
+	Engine string
+	Schema map[string]FieldType
+}
+
+func (r SchemaUpdateRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "schema")
+	if err != nil {
+		return nil, err
+	}
+
+	for field, typ := range r.Schema {
+		switch typ {
+		case FieldTypeText, FieldTypeNumber, FieldTypeDate, FieldTypeGeolocation:
+		default:
+			return nil, fmt.Errorf("unsupported type %q of field %q", typ, field)
+		}
+	}
+
+	body, err := json.Marshal(r.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := api.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	if len(body) > 0 {
+		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
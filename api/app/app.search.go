@@ -1,8 +1,9 @@
 package app
 
 import (
+	"bytes"
 	"context"
-	"fmt"
+	"encoding/json"
 	"io"
 	"net/http"
 
@@ -17,6 +18,12 @@ func (Search) WithContext(ctx context.Context) func(*SearchRequest) {
 	}
 }
 
+func (Search) WithAuth(apiKey string) func(*SearchRequest) {
+	return func(r *SearchRequest) {
+		r.Auth = apiKey
+	}
+}
+
 func (Search) WithEngine(engine string) func(*SearchRequest) {
 	return func(r *SearchRequest) {
 		r.Engine = engine
@@ -29,6 +36,13 @@ func (Search) WithBody(body io.Reader) func(*SearchRequest) {
 	}
 }
 
+// WithQuery sets a typed query as the body of the request, it takes precedence over WithBody.
+func (Search) WithQuery(query SearchQuery) func(*SearchRequest) {
+	return func(r *SearchRequest) {
+		r.Query = &query
+	}
+}
+
 func newSearchFunc(tp api.Transport) Search {
 	return func(o ...func(*SearchRequest)) (*api.Response, error) {
 		r := SearchRequest{
@@ -47,10 +61,23 @@ type SearchRequest struct {
 	api.Request
 	Engine string
 	Body   io.Reader
+	Query  *SearchQuery
 }
 
 func (r SearchRequest) Do() (*api.Response, error) {
-	path := fmt.Sprintf("/api/as/v1/engines/%s/search", r.Engine)
+	path, err := enginePath(r.Engine, "search")
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Query != nil {
+		body, err := json.Marshal(r.Query)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = bytes.NewReader(body)
+	}
+
 	req, err := api.NewRequest(http.MethodPost, path, r.Body)
 
 	if err != nil {
@@ -61,6 +88,10 @@ func (r SearchRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	if r.Body != nil {
 		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
 	}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// maxSearchPages is the deepest page App Search allows to request.
+	maxSearchPages = 100
+	// maxSearchPageSize is the largest page size App Search allows to request.
+	maxSearchPageSize = 1000
+)
+
+// ErrPaginationLimit is returned by SearchIterator when the results exceed
+// the pagination limit and no partition field is set.
+var ErrPaginationLimit = errors.New("search results exceed the limit of 100 pages, set a partition field to walk all of them")
+
+// SearchIterator walks every result of a query page by page.
+//
+// App Search refuses pages beyond the 100th, when PartitionField is set the results are
+// sorted by that field and once the limit is reached the iterator starts over with a range
+// filter from the last value seen, so all matching documents are returned. PartitionField
+// must be a number or date field present in every document, and it overrides the Sort of the query.
+//
+//	it := client.AppSearch.Search.Iterator(ctx, engine, query, client.AppSearch.Search.WithAuth(searchKey))
+//	it.PartitionField = "created_at"
+//	for it.Next() {
+//		result := it.Result()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type SearchIterator struct {
+	PartitionField string
+
+	search  Search
+	ctx     context.Context
+	engine  string
+	query   SearchQuery
+	options []func(*SearchRequest)
+
+	page    int
+	lower   json.RawMessage
+	last    json.RawMessage
+	seen    map[string]bool
+	results []SearchResult
+	current SearchResult
+	done    bool
+	err     error
+}
+
+// Iterator returns a SearchIterator over all results of the query, Page.Size of the query is
+// used as the page size and defaults to the maximum of 1000. The options, e.g. WithAuth,
+// are applied to every search request.
+func (s Search) Iterator(ctx context.Context, engine string, query SearchQuery, o ...func(*SearchRequest)) *SearchIterator {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &SearchIterator{
+		search:  s,
+		ctx:     ctx,
+		engine:  engine,
+		query:   query,
+		options: o,
+		seen:    map[string]bool{},
+	}
+}
+
+// Next advances to the next result, it returns false when there are no more results or an error occurs.
+func (it *SearchIterator) Next() bool {
+	for len(it.results) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.err = it.fetch()
+	}
+
+	it.current, it.results = it.results[0], it.results[1:]
+	return true
+}
+
+// Result returns the current result.
+func (it *SearchIterator) Result() SearchResult {
+	return it.current
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+func (it *SearchIterator) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return err
+	}
+
+	if it.page == maxSearchPages {
+		if len(it.PartitionField) == 0 {
+			return ErrPaginationLimit
+		}
+		if bytes.Equal(it.last, it.lower) {
+			return fmt.Errorf("too many results share the value %s of field %s", it.last, it.PartitionField)
+		}
+		it.lower = it.last
+		it.page = 0
+	}
+	it.page++
+
+	resp, err := it.search(append(it.options[:len(it.options):len(it.options)],
+		it.search.WithContext(it.ctx),
+		it.search.WithEngine(it.engine),
+		it.search.WithQuery(it.pageQuery()),
+	)...)
+	if err != nil {
+		return err
+	}
+
+	sr, err := DecodeSearchResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range sr.Results {
+		if len(it.PartitionField) > 0 {
+			value := r.Fields[it.PartitionField].Raw
+			if !bytes.Equal(value, it.last) {
+				it.last = value
+				it.seen = map[string]bool{}
+			} else if it.seen[r.Meta.ID] {
+				// already returned before the range filter moved to this value
+				continue
+			}
+			it.seen[r.Meta.ID] = true
+		}
+		it.results = append(it.results, r)
+	}
+
+	if len(sr.Results) == 0 || it.page >= sr.Meta.Page.TotalPages {
+		it.done = true
+	}
+	return nil
+}
+
+func (it *SearchIterator) pageQuery() SearchQuery {
+	q := it.query
+
+	size := maxSearchPageSize
+	if q.Page != nil && q.Page.Size > 0 {
+		size = q.Page.Size
+	}
+	q.Page = &Page{Current: it.page, Size: size}
+
+	if len(it.PartitionField) == 0 {
+		return q
+	}
+
+	q.Sort = []Sort{{Field: it.PartitionField, Order: "asc"}}
+
+	if q.ResultFields != nil {
+		fields := make(map[string]ResultField, len(q.ResultFields)+1)
+		for name, f := range q.ResultFields {
+			fields[name] = f
+		}
+		if f, ok := fields[it.PartitionField]; !ok || f.Raw == nil {
+			f.Raw = &RawField{}
+			fields[it.PartitionField] = f
+		}
+		q.ResultFields = fields
+	}
+
+	if it.lower != nil {
+		filter := FilterRange(it.PartitionField, it.lower, nil)
+		if q.Filters != nil {
+			filter = AllOf(*q.Filters, filter)
+		}
+		q.Filters = &filter
+	}
+	return q
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"encoding/json"
+)
+
+// SearchQuery is the body of a search request.
+// see https://www.elastic.co/guide/en/app-search/current/search.html for details.
+type SearchQuery struct {
+	Query        string                 `json:"query"`
+	Filters      *Filter                `json:"filters,omitempty"`
+	Facets       map[string][]Facet     `json:"facets,omitempty"`
+	Boosts       map[string][]Boost     `json:"boosts,omitempty"`
+	SearchFields map[string]SearchField `json:"search_fields,omitempty"`
+	ResultFields map[string]ResultField `json:"result_fields,omitempty"`
+	Sort         []Sort                 `json:"sort,omitempty"`
+	Group        *Group                 `json:"group,omitempty"`
+	Page         *Page                  `json:"page,omitempty"`
+	Precision    int                    `json:"precision,omitempty"`
+	Analytics    *Analytics             `json:"analytics,omitempty"`
+}
+
+// Filter narrows down results, it is either a condition on a single field
+// or a combination of filters with all, any and none.
+// see https://www.elastic.co/guide/en/app-search/current/filters.html for details.
+type Filter struct {
+	All  []Filter
+	Any  []Filter
+	None []Filter
+
+	Field string
+	Value interface{}
+}
+
+// FilterValues matches documents whose field equals any of the values.
+func FilterValues(field string, values ...interface{}) Filter {
+	if len(values) == 1 {
+		return Filter{Field: field, Value: values[0]}
+	}
+	return Filter{Field: field, Value: values}
+}
+
+// FilterRange matches documents whose number or date field is within [from, to), either end may be nil.
+func FilterRange(field string, from, to interface{}) Filter {
+	r := map[string]interface{}{}
+	if from != nil {
+		r["from"] = from
+	}
+	if to != nil {
+		r["to"] = to
+	}
+	return Filter{Field: field, Value: r}
+}
+
+// FilterGeo matches documents whose geolocation field is within distance of center, e.g. "37.386483, -122.083842".
+func FilterGeo(field, center string, distance float64, unit string) Filter {
+	return Filter{Field: field, Value: map[string]interface{}{
+		"center":   center,
+		"distance": distance,
+		"unit":     unit,
+	}}
+}
+
+// AllOf matches documents satisfying every filter.
+func AllOf(filters ...Filter) Filter {
+	return Filter{All: filters}
+}
+
+// AnyOf matches documents satisfying at least one filter.
+func AnyOf(filters ...Filter) Filter {
+	return Filter{Any: filters}
+}
+
+// NoneOf matches documents satisfying none of the filters.
+func NoneOf(filters ...Filter) Filter {
+	return Filter{None: filters}
+}
+
+func (f Filter) MarshalJSON() ([]byte, error) {
+	if len(f.Field) > 0 {
+		return json.Marshal(map[string]interface{}{
+			f.Field: f.Value,
+		})
+	}
+
+	m := map[string][]Filter{}
+	if len(f.All) > 0 {
+		m["all"] = f.All
+	}
+	if len(f.Any) > 0 {
+		m["any"] = f.Any
+	}
+	if len(f.None) > 0 {
+		m["none"] = f.None
+	}
+	return json.Marshal(m)
+}
+
+// Facet requests counts of documents per value or per range of a field.
+// see https://www.elastic.co/guide/en/app-search/current/facets.html for details.
+type Facet struct {
+	// Type is either "value" or "range".
+	Type   string            `json:"type"`
+	Name   string            `json:"name,omitempty"`
+	Sort   map[string]string `json:"sort,omitempty"`
+	Size   int               `json:"size,omitempty"`
+	Ranges []FacetRange      `json:"ranges,omitempty"`
+	// Center and Unit apply to range facets of geolocation fields.
+	Center string `json:"center,omitempty"`
+	Unit   string `json:"unit,omitempty"`
+}
+
+type FacetRange struct {
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+	Name string      `json:"name,omitempty"`
+}
+
+// Sort orders results by a field, use "_score" to sort by relevance.
+type Sort struct {
+	Field string
+	// Order is either "asc" or "desc".
+	Order string
+}
+
+func (s Sort) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{
+		s.Field: s.Order,
+	})
+}
+
+// Group collapses results sharing the same value of a field.
+type Group struct {
+	Field    string            `json:"field"`
+	Size     int               `json:"size,omitempty"`
+	Sort     map[string]string `json:"sort,omitempty"`
+	Collapse bool              `json:"collapse,omitempty"`
+}
+
+type Page struct {
+	Current int `json:"current,omitempty"`
+	Size    int `json:"size,omitempty"`
+}
+
+// Analytics attaches tags to the query so it can be filtered in analytics.
+type Analytics struct {
+	Tags []string `json:"tags,omitempty"`
+}
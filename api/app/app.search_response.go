@@ -0,0 +1,148 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nevill/jiangjing/api"
+)
+
+// SearchResponse is the decoded body of a search response.
+type SearchResponse struct {
+	Meta    SearchMeta               `json:"meta"`
+	Results []SearchResult           `json:"results"`
+	Facets  map[string][]FacetResult `json:"facets,omitempty"`
+}
+
+type SearchMeta struct {
+	RequestID string   `json:"request_id"`
+	Alerts    []string `json:"alerts"`
+	Warnings  []string `json:"warnings"`
+	Precision int      `json:"precision"`
+	Page      PageMeta `json:"page"`
+	Engine    struct {
+		Name string     `json:"name"`
+		Type EngineType `json:"type"`
+	} `json:"engine"`
+}
+
+// PageMeta describes the pagination of a response.
+type PageMeta struct {
+	Current      int `json:"current"`
+	Size         int `json:"size"`
+	TotalPages   int `json:"total_pages"`
+	TotalResults int `json:"total_results"`
+}
+
+// ResultMeta holds the metadata of a single result.
+type ResultMeta struct {
+	ID     string  `json:"id"`
+	Engine string  `json:"engine"`
+	Score  float64 `json:"score"`
+}
+
+// FieldValue is a field of a result, Raw is kept undecoded so it can be decoded into any type.
+type FieldValue struct {
+	Raw     json.RawMessage `json:"raw,omitempty"`
+	Snippet *string         `json:"snippet,omitempty"`
+}
+
+// SearchResult is a single document of a search response, Group holds the
+// other documents of its group when the query groups the results.
+type SearchResult struct {
+	Meta   ResultMeta
+	Fields map[string]FieldValue
+	Group  []SearchResult
+}
+
+func (r *SearchResult) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	r.Fields = make(map[string]FieldValue, len(fields))
+	for name, value := range fields {
+		if name == "_meta" {
+			if err := json.Unmarshal(value, &r.Meta); err != nil {
+				return fmt.Errorf("cannot parse _meta: %s", err)
+			}
+			continue
+		}
+		if name == "_group" {
+			if err := json.Unmarshal(value, &r.Group); err != nil {
+				return fmt.Errorf("cannot parse _group: %s", err)
+			}
+			continue
+		}
+
+		var v FieldValue
+		if err := json.Unmarshal(value, &v); err != nil {
+			return fmt.Errorf("cannot parse field %s: %s", name, err)
+		}
+		r.Fields[name] = v
+	}
+	return nil
+}
+
+// DecodeRaw decodes the raw values of the result into v, as if they were the fields of a JSON object.
+func (r SearchResult) DecodeRaw(v interface{}) error {
+	raw := make(map[string]json.RawMessage, len(r.Fields))
+	for name, value := range r.Fields {
+		if value.Raw != nil {
+			raw[name] = value.Raw
+		}
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// FacetResult holds the counts of a requested facet.
+type FacetResult struct {
+	Type string      `json:"type"`
+	Name string      `json:"name,omitempty"`
+	Data []FacetData `json:"data"`
+}
+
+// FacetData is a bucket of a facet, Value is set for value facets while From and To are set for range facets.
+type FacetData struct {
+	Value interface{} `json:"value,omitempty"`
+	From  interface{} `json:"from,omitempty"`
+	To    interface{} `json:"to,omitempty"`
+	Name  string      `json:"name,omitempty"`
+	Count int         `json:"count"`
+}
+
+// DecodeSearchResponse reads the response of Search and closes its body.
+func DecodeSearchResponse(res *api.Response) (*SearchResponse, error) {
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, api.NewError(res)
+	}
+
+	var sr SearchResponse
+	if err := json.NewDecoder(res.Body).Decode(&sr); err != nil {
+		return nil, fmt.Errorf("error parsing the response body: %s", err)
+	}
+	return &sr, nil
+}
+
+// DecodeMultiSearchResponse reads the response of MultiSearch and closes its body.
+func DecodeMultiSearchResponse(res *api.Response) ([]SearchResponse, error) {
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, api.NewError(res)
+	}
+
+	var sr []SearchResponse
+	if err := json.NewDecoder(res.Body).Decode(&sr); err != nil {
+		return nil, fmt.Errorf("error parsing the response body: %s", err)
+	}
+	return sr, nil
+}
@@ -0,0 +1,288 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/nevill/jiangjing/api"
+)
+
+// BoostType is the type of a boost.
+type BoostType string
+
+const (
+	BoostTypeValue      BoostType = "value"
+	BoostTypeFunctional BoostType = "functional"
+	BoostTypeProximity  BoostType = "proximity"
+)
+
+// Boost raises the score of documents by the value of a field.
+// see https://www.elastic.co/guide/en/app-search/current/boosts.html for details.
+type Boost struct {
+	Type BoostType `json:"type"`
+	// Value holds the values to match for value boosts.
+	Value []interface{} `json:"value,omitempty"`
+	// Operation is either "add" or "multiply", used by value and functional boosts.
+	Operation string `json:"operation,omitempty"`
+	// Function is one of "linear", "exponential", "logarithmic" or "gaussian",
+	// functional boosts accept the first three while proximity boosts accept all but "logarithmic".
+	Function string `json:"function,omitempty"`
+	// Center is the origin of proximity boosts, a number, a date or a geolocation.
+	Center interface{} `json:"center,omitempty"`
+	Factor float64     `json:"factor"`
+}
+
+// SearchField sets the weight of a text field in relevance calculation.
+type SearchField struct {
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// RawField requests the raw value of a field, Size truncates text fields.
+type RawField struct {
+	Size int `json:"size,omitempty"`
+}
+
+// SnippetField requests a highlighted snippet of a text field.
+type SnippetField struct {
+	Size     int  `json:"size,omitempty"`
+	Fallback bool `json:"fallback,omitempty"`
+}
+
+// ResultField controls how a field is returned in results.
+type ResultField struct {
+	Raw     *RawField     `json:"raw,omitempty"`
+	Snippet *SnippetField `json:"snippet,omitempty"`
+}
+
+// RelevanceTuning holds the search settings of an engine.
+// see https://www.elastic.co/guide/en/app-search/current/search-settings.html for details.
+type RelevanceTuning struct {
+	SearchFields map[string]SearchField `json:"search_fields,omitempty"`
+	ResultFields map[string]ResultField `json:"result_fields,omitempty"`
+	Boosts       map[string][]Boost     `json:"boosts,omitempty"`
+	Precision    int                    `json:"precision,omitempty"`
+}
+
+type SearchSettings struct {
+	Get    SearchSettingsGet
+	Update SearchSettingsUpdate
+	Reset  SearchSettingsReset
+}
+
+// SearchSettingsGet retrieves the current search settings of the engine.
+// see https://www.elastic.co/guide/en/app-search/current/search-settings.html#search-settings-show for details.
+type SearchSettingsGet func(name string, o ...func(*SearchSettingsGetRequest)) (*api.Response, error)
+
+func (h SearchSettingsGet) WithContext(ctx context.Context) func(*SearchSettingsGetRequest) {
+	return func(r *SearchSettingsGetRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h SearchSettingsGet) WithAuth(apiKey string) func(*SearchSettingsGetRequest) {
+	return func(r *SearchSettingsGetRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newSearchSettingsGetFunc(tp api.Transport) SearchSettingsGet {
+	return func(name string, o ...func(*SearchSettingsGetRequest)) (*api.Response, error) {
+		r := SearchSettingsGetRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine: name,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type SearchSettingsGetRequest struct {
+	api.Request
+	Engine string
+}
+
+func (r SearchSettingsGetRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "search_settings")
+	if err != nil {
+		return nil, err
+	}
+	req, err := api.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// SearchSettingsUpdate replaces the search settings of the engine.
+// see https://www.elastic.co/guide/en/app-search/current/search-settings.html#search-settings-update for details.
+type SearchSettingsUpdate func(name string, settings RelevanceTuning, o ...func(*SearchSettingsUpdateRequest)) (*api.Response, error)
+
+func (h SearchSettingsUpdate) WithContext(ctx context.Context) func(*SearchSettingsUpdateRequest) {
+	return func(r *SearchSettingsUpdateRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h SearchSettingsUpdate) WithAuth(apiKey string) func(*SearchSettingsUpdateRequest) {
+	return func(r *SearchSettingsUpdateRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newSearchSettingsUpdateFunc(tp api.Transport) SearchSettingsUpdate {
+	return func(name string, settings RelevanceTuning, o ...func(*SearchSettingsUpdateRequest)) (*api.Response, error) {
+		r := SearchSettingsUpdateRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine:   name,
+			Settings: settings,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type SearchSettingsUpdateRequest struct {
+	api.Request
+	Engine   string
+	Settings RelevanceTuning
+}
+
+func (r SearchSettingsUpdateRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "search_settings")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(r.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := api.NewRequest(http.MethodPut, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	if len(body) > 0 {
+		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
+
+// SearchSettingsReset restores the default search settings of the engine.
+// see https://www.elastic.co/guide/en/app-search/current/search-settings.html#search-settings-reset for details.
+type SearchSettingsReset func(name string, o ...func(*SearchSettingsResetRequest)) (*api.Response, error)
+
+func (h SearchSettingsReset) WithContext(ctx context.Context) func(*SearchSettingsResetRequest) {
+	return func(r *SearchSettingsResetRequest) {
+		r.Context = ctx
+	}
+}
+
+func (h SearchSettingsReset) WithAuth(apiKey string) func(*SearchSettingsResetRequest) {
+	return func(r *SearchSettingsResetRequest) {
+		r.Auth = apiKey
+	}
+}
+
+func newSearchSettingsResetFunc(tp api.Transport) SearchSettingsReset {
+	return func(name string, o ...func(*SearchSettingsResetRequest)) (*api.Response, error) {
+		r := SearchSettingsResetRequest{
+			Request: api.Request{
+				Transport: tp,
+			},
+			Engine: name,
+		}
+		for _, f := range o {
+			f(&r)
+		}
+		return r.Do()
+	}
+}
+
+type SearchSettingsResetRequest struct {
+	api.Request
+	Engine string
+}
+
+func (r SearchSettingsResetRequest) Do() (*api.Response, error) {
+	path, err := enginePath(r.Engine, "search_settings", "reset")
+	if err != nil {
+		return nil, err
+	}
+	req, err := api.NewRequest(http.MethodPost, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Context != nil {
+		req = req.WithContext(r.Context)
+	}
+
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
+	res, err := r.Transport.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := api.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}
+
+	return &response, nil
+}
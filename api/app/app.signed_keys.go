@@ -0,0 +1,94 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SearchKeyRestrictions holds the search options baked into a signed search key,
+// they override whatever the search request asks for.
+// see https://www.elastic.co/guide/en/app-search/current/authentication.html#authentication-signed for details.
+type SearchKeyRestrictions struct {
+	SearchFields map[string]SearchField `json:"search_fields,omitempty"`
+	ResultFields map[string]ResultField `json:"result_fields,omitempty"`
+	Filters      interface{}            `json:"filters,omitempty"`
+	Facets       interface{}            `json:"facets,omitempty"`
+}
+
+// SignedSearchKey is the payload of a signed search key.
+type SignedSearchKey struct {
+	APIKeyName string `json:"api_key_name"`
+	SearchKeyRestrictions
+}
+
+var signedKeyHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// NewSignedSearchKey signs the restrictions with a search key, keyName is the name of that search key.
+// The returned key can be used in place of the search key, no request is made to App Search.
+func NewSignedSearchKey(searchKey, keyName string, restrictions SearchKeyRestrictions) (string, error) {
+	if !strings.HasPrefix(searchKey, "search-") {
+		return "", errors.New("cannot sign with a key which is not a search key")
+	}
+	if len(keyName) == 0 {
+		return "", errors.New("cannot sign without the name of search key")
+	}
+
+	payload, err := json.Marshal(SignedSearchKey{
+		APIKeyName:            keyName,
+		SearchKeyRestrictions: restrictions,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := signedKeyHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + signKey(unsigned, searchKey), nil
+}
+
+// VerifySignedSearchKey checks the signature of a signed search key and returns its payload.
+func VerifySignedSearchKey(signedKey, searchKey string) (*SignedSearchKey, error) {
+	parts := strings.Split(signedKey, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed signed search key")
+	}
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode header: %s", err)
+	}
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(header, &h); err != nil {
+		return nil, fmt.Errorf("cannot parse header: %s", err)
+	}
+	if h.Alg != "HS256" {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", h.Alg)
+	}
+
+	expected := signKey(parts[0]+"."+parts[1], searchKey)
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, errors.New("signature mismatch")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode payload: %s", err)
+	}
+	var key SignedSearchKey
+	if err := json.Unmarshal(payload, &key); err != nil {
+		return nil, fmt.Errorf("cannot parse payload: %s", err)
+	}
+	return &key, nil
+}
+
+func signKey(unsigned, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
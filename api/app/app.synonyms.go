@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 
@@ -29,12 +28,35 @@ func (h SynonymsList) WithContext(ctx context.Context) func(*SynonymsListRequest
 	}
 }
 
+func (h SynonymsList) WithAuth(apiKey string) func(*SynonymsListRequest) {
+	return func(r *SynonymsListRequest) {
+		r.Auth = apiKey
+	}
+}
+
 func (h SynonymsList) WithBody(body io.Reader) func(*SynonymsListRequest) {
 	return func(r *SynonymsListRequest) {
 		r.Body = body
 	}
 }
 
+// WithPage sets the page number and the number of synonym sets per page.
+func (h SynonymsList) WithPage(current, size int) func(*SynonymsListRequest) {
+	return func(r *SynonymsListRequest) {
+		r.Current = current
+		r.Size = size
+	}
+}
+
+// All returns a ListIterator over synonym sets of all pages.
+func (h SynonymsList) All(name string, o ...func(*SynonymsListRequest)) *ListIterator {
+	return newListIterator(func(page int) (*api.Response, error) {
+		return h(name, append(o[:len(o):len(o)], func(r *SynonymsListRequest) {
+			r.Current = page
+		})...)
+	})
+}
+
 func newSynonymsListFunc(tp api.Transport) SynonymsList {
 	return func(name string, o ...func(*SynonymsListRequest)) (*api.Response, error) {
 		r := SynonymsListRequest{
@@ -52,12 +74,18 @@ func newSynonymsListFunc(tp api.Transport) SynonymsList {
 
 type SynonymsListRequest struct {
 	api.Request
-	Engine string
-	Body   io.Reader
+	Engine  string
+	Body    io.Reader
+	Current int
+	Size    int
 }
 
 func (r SynonymsListRequest) Do() (*api.Response, error) {
-	path := fmt.Sprintf("/api/as/v1/engines/%s/synonyms", r.Engine)
+	path, err := enginePath(r.Engine, "synonyms")
+	if err != nil {
+		return nil, err
+	}
+	path += pageParams(r.Current, r.Size)
 
 	req, err := api.NewRequest(http.MethodGet, path, r.Body)
 	if err != nil {
@@ -68,6 +96,10 @@ func (r SynonymsListRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	if r.Body != nil {
 		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
 	}
@@ -96,6 +128,12 @@ func (h SynonymsGet) WithContext(ctx context.Context) func(*SynonymsGetRequest)
 	}
 }
 
+func (h SynonymsGet) WithAuth(apiKey string) func(*SynonymsGetRequest) {
+	return func(r *SynonymsGetRequest) {
+		r.Auth = apiKey
+	}
+}
+
 func newSynonymsGetFunc(tp api.Transport) SynonymsGet {
 	return func(name, id string, o ...func(*SynonymsGetRequest)) (*api.Response, error) {
 		r := SynonymsGetRequest{
@@ -119,7 +157,10 @@ type SynonymsGetRequest struct {
 }
 
 func (r SynonymsGetRequest) Do() (*api.Response, error) {
-	path := fmt.Sprintf("/api/as/v1/engines/%s/synonyms/%s", r.Engine, r.Id)
+	path, err := enginePath(r.Engine, "synonyms", r.Id)
+	if err != nil {
+		return nil, err
+	}
 	req, err := api.NewRequest(http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
@@ -129,6 +170,10 @@ func (r SynonymsGetRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	res, err := r.Transport.Perform(req)
 	if err != nil {
 		return nil, err
@@ -153,6 +198,12 @@ func (h SynonymsCreate) WithContext(ctx context.Context) func(*SynonymsCreateReq
 	}
 }
 
+func (h SynonymsCreate) WithAuth(apiKey string) func(*SynonymsCreateRequest) {
+	return func(r *SynonymsCreateRequest) {
+		r.Auth = apiKey
+	}
+}
+
 func newSynonymsCreateFunc(tp api.Transport) SynonymsCreate {
 	return func(name string, synonyms []string, o ...func(*SynonymsCreateRequest)) (*api.Response, error) {
 		r := SynonymsCreateRequest{
@@ -176,7 +227,10 @@ type SynonymsCreateRequest struct {
 }
 
 func (r SynonymsCreateRequest) Do() (*api.Response, error) {
-	path := fmt.Sprintf("/api/as/v1/engines/%s/synonyms", r.Engine)
+	path, err := enginePath(r.Engine, "synonyms")
+	if err != nil {
+		return nil, err
+	}
 
 	body, err := json.Marshal(map[string]interface{}{
 		"synonyms": r.Synonyms,
@@ -194,6 +248,10 @@ func (r SynonymsCreateRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	if len(body) > 0 {
 		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
 	}
@@ -222,6 +280,12 @@ func (h SynonymsUpdate) WithContext(ctx context.Context) func(*SynonymsUpdateReq
 	}
 }
 
+func (h SynonymsUpdate) WithAuth(apiKey string) func(*SynonymsUpdateRequest) {
+	return func(r *SynonymsUpdateRequest) {
+		r.Auth = apiKey
+	}
+}
+
 func newSynonymsUpdateFunc(tp api.Transport) SynonymsUpdate {
 	return func(name, id string, synonyms []string, o ...func(*SynonymsUpdateRequest)) (*api.Response, error) {
 		r := SynonymsUpdateRequest{
@@ -247,7 +311,10 @@ type SynonymsUpdateRequest struct {
 }
 
 func (r SynonymsUpdateRequest) Do() (*api.Response, error) {
-	path := fmt.Sprintf("/api/as/v1/engines/%s/synonyms/%s", r.Engine, r.Id)
+	path, err := enginePath(r.Engine, "synonyms", r.Id)
+	if err != nil {
+		return nil, err
+	}
 
 	body, err := json.Marshal(map[string]interface{}{
 		"id":       r.Id,
@@ -266,6 +333,10 @@ func (r SynonymsUpdateRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	if len(body) > 0 {
 		req.Header[api.HeaderContentType] = api.HeaderContentTypeJSON
 	}
@@ -294,6 +365,12 @@ func (h SynonymsDelete) WithContext(ctx context.Context) func(*SynonymsDeleteReq
 	}
 }
 
+func (h SynonymsDelete) WithAuth(apiKey string) func(*SynonymsDeleteRequest) {
+	return func(r *SynonymsDeleteRequest) {
+		r.Auth = apiKey
+	}
+}
+
 func newSynonymsDeleteFunc(tp api.Transport) SynonymsDelete {
 	return func(name, id string, o ...func(*SynonymsDeleteRequest)) (*api.Response, error) {
 		r := SynonymsDeleteRequest{
@@ -317,7 +394,10 @@ type SynonymsDeleteRequest struct {
 }
 
 func (r SynonymsDeleteRequest) Do() (*api.Response, error) {
-	path := fmt.Sprintf("/api/as/v1/engines/%s/synonyms/%s", r.Engine, r.Id)
+	path, err := enginePath(r.Engine, "synonyms", r.Id)
+	if err != nil {
+		return nil, err
+	}
 	req, err := api.NewRequest(http.MethodDelete, path, nil)
 	if err != nil {
 		return nil, err
@@ -327,6 +407,10 @@ func (r SynonymsDeleteRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	res, err := r.Transport.Perform(req)
 	if err != nil {
 		return nil, err
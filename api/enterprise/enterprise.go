@@ -29,6 +29,12 @@ func (h Health) WithContext(ctx context.Context) func(*HealthRequest) {
 	}
 }
 
+func (h Health) WithAuth(apiKey string) func(*HealthRequest) {
+	return func(r *HealthRequest) {
+		r.Auth = apiKey
+	}
+}
+
 type HealthRequest struct {
 	api.Request
 }
@@ -45,6 +51,10 @@ func (r HealthRequest) Do() (*api.Response, error) {
 		req = req.WithContext(r.Context)
 	}
 
+	if len(r.Auth) > 0 {
+		req.Header.Set(api.HeaderAuthorization, "Bearer "+r.Auth)
+	}
+
 	res, err := r.Transport.Perform(req)
 	if err != nil {
 		return nil, err
@@ -1,26 +1,90 @@
 package jiangjing
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"net/url"
+	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/elastic/elastic-transport-go/v8/elastictransport"
+	"github.com/nevill/jiangjing/api"
 	"github.com/nevill/jiangjing/api/app"
 	"github.com/nevill/jiangjing/api/enterprise"
 )
 
+const (
+	envURL      = "ENTERPRISE_SEARCH_URL"
+	envCloudID  = "ENTERPRISE_SEARCH_CLOUD_ID"
+	envUsername = "ENTERPRISE_SEARCH_USERNAME"
+	envPassword = "ENTERPRISE_SEARCH_PASSWORD"
+	envAPIKey   = "ENTERPRISE_SEARCH_API_KEY"
+	envCACert   = "ENTERPRISE_SEARCH_CA_CERT"
+)
+
 type Client struct {
 	EnterpriseSearch
 	AppSearch AppSearch
 }
 
 type Config struct {
-	Address  string
+	Address string
+	// CloudID is the Cloud ID of an Elastic Cloud deployment, which holds the address of its Enterprise Search.
+	CloudID string
+	// Addresses are the URLs of several nodes, requests are sent to them in turn.
+	// A node failing with a network error is marked dead and is resurrected later.
+	Addresses []string
+
 	Username string
 	Password string
-	Token    string
+	// Token is a bearer token of Enterprise Search, e.g. an Elasticsearch service account token.
+	Token string
+	// APIKey is an App Search private or search key, it is sent as a bearer token.
+	// The key of a single request can be overridden with its WithAuth option.
+	APIKey string
+
+	// CACert holds the PEM encoded certificates of the authorities trusted to verify the server.
+	CACert []byte
+	// CertificateFingerprint is the hex encoded SHA256 fingerprint of a certificate of the server,
+	// when set the server is trusted if it presents that certificate.
+	CertificateFingerprint string
+	// ClientCert and ClientKey are the PEM encoded certificate and key presented to the server.
+	ClientCert []byte
+	ClientKey  []byte
+	// InsecureSkipVerify disables verifying the certificate of the server, it is for testing only.
+	InsecureSkipVerify bool
+
+	// Transport is the HTTP transport used to send requests, defaults to http.DefaultTransport.
+	// TLS options can only be combined with an *http.Transport.
+	Transport http.RoundTripper
+	// APITransport replaces the transport built from the options above, e.g. for testing.
+	// It cannot be set along with Address, Addresses, CloudID, Username, Password, Token, APIKey,
+	// CACert, CertificateFingerprint, ClientCert, ClientKey, InsecureSkipVerify or Transport.
+	APITransport api.Transport
+
+	// ReturnErrors makes every request return an *api.Error for a non-2xx response.
+	ReturnErrors bool
+
+	// DisableRetry disables retrying failed requests, otherwise requests failing with one of
+	// RetryOnStatus, or with a network error for one of RetryOnMethods, are retried up to MaxRetries times.
+	// The retries of a single request can be overridden with api.ContextWithRetry.
+	DisableRetry   bool
+	MaxRetries     int                             // defaults to 3
+	RetryOnStatus  []int                           // defaults to 429, 502, 503 and 504
+	RetryOnMethods []string                        // defaults to the idempotent methods, see api.DefaultRetryOnMethods
+	RetryBackoff   func(attempt int) time.Duration // defaults to api.DefaultRetryBackoff
+	MaxRetryAfter  time.Duration                   // caps the delay of Retry-After, defaults to 30s
 }
 
 type EnterpriseSearch struct {
@@ -31,43 +95,377 @@ type AppSearch struct {
 	*app.API
 }
 
-func addrToUrls(address string) ([]*url.URL, error) {
-	u, err := url.Parse(strings.TrimRight(address, "/"))
+func addrToUrls(addresses ...string) ([]*url.URL, error) {
+	var urls []*url.URL
+	for _, address := range addresses {
+		u, err := url.Parse(strings.TrimRight(address, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse url: %v", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("cannot use url %q: scheme must be http or https", address)
+		}
+		urls = append(urls, u)
+	}
+
+	return urls, nil
+}
+
+// cloudIDToAddress decodes the address of Enterprise Search from a Cloud ID, which is
+// "<name>:" followed by the base64 encoded "<domain>[:<port>]$<es>$<kibana>$<ent>".
+func cloudIDToAddress(cloudID string) (string, error) {
+	if i := strings.LastIndex(cloudID, ":"); i >= 0 {
+		cloudID = cloudID[i+1:]
+	}
+
+	data, err := base64.StdEncoding.DecodeString(cloudID)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse url: %v", err)
+		return "", fmt.Errorf("cannot decode cloud id: %s", err)
+	}
+
+	parts := strings.Split(string(data), "$")
+	if len(parts) < 4 || len(parts[0]) == 0 || len(parts[3]) == 0 {
+		return "", errors.New("cannot decode cloud id: no Enterprise Search in it")
+	}
+
+	domain, port := parts[0], "443"
+	if i := strings.LastIndex(domain, ":"); i >= 0 {
+		domain, port = domain[:i], domain[i+1:]
+	}
+
+	// the component may carry its own port, e.g. <ent>:9243
+	ent := parts[3]
+	if i := strings.LastIndex(ent, ":"); i >= 0 {
+		ent, port = ent[:i], ent[i+1:]
 	}
 
-	return []*url.URL{u}, nil
+	return fmt.Sprintf("https://%s.%s:%s", ent, domain, port), nil
 }
 
-func NewClient(cfg Config) (*Client, error) {
-	urls, err := addrToUrls(cfg.Address)
+// newRoundTripper returns the transport of the config carrying its TLS options.
+func newRoundTripper(cfg Config) (http.RoundTripper, error) {
+	if cfg.CACert == nil && len(cfg.CertificateFingerprint) == 0 &&
+		cfg.ClientCert == nil && cfg.ClientKey == nil && !cfg.InsecureSkipVerify {
+		return cfg.Transport, nil
+	}
+
+	var tp *http.Transport
+	switch t := cfg.Transport.(type) {
+	case nil:
+		if dt, ok := http.DefaultTransport.(*http.Transport); ok {
+			tp = dt.Clone()
+		} else {
+			// http.DefaultTransport is replaced, e.g. by instrumentation
+			tp = newDefaultTransport()
+		}
+	case *http.Transport:
+		tp = t.Clone()
+	default:
+		return nil, fmt.Errorf("cannot set TLS options for transport of type %T", cfg.Transport)
+	}
+
+	tlsConfig := &tls.Config{}
+	if tp.TLSClientConfig != nil {
+		tlsConfig = tp.TLSClientConfig.Clone()
+	}
+	tlsConfig.InsecureSkipVerify = tlsConfig.InsecureSkipVerify || cfg.InsecureSkipVerify
+
+	if cfg.CACert != nil {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(cfg.CACert) {
+			return nil, errors.New("cannot add CA certificate")
+		}
+	}
+
+	if cfg.ClientCert != nil || cfg.ClientKey != nil {
+		cert, err := tls.X509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(cfg.CertificateFingerprint) > 0 {
+		fingerprint, err := decodeFingerprint(cfg.CertificateFingerprint)
+		if err != nil {
+			return nil, err
+		}
+		// the pinned certificate replaces verifying the chain, e.g. of a self-signed certificate
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			for _, raw := range rawCerts {
+				digest := sha256.Sum256(raw)
+				if bytes.Equal(digest[:], fingerprint) {
+					return nil
+				}
+			}
+			return fmt.Errorf("certificate fingerprint mismatch, expect %x", fingerprint)
+		}
+	}
+
+	tp.TLSClientConfig = tlsConfig
+	return tp, nil
+}
+
+// newDefaultTransport returns a transport with the settings of http.DefaultTransport.
+func newDefaultTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// decodeFingerprint decodes a hex encoded SHA256 fingerprint, the colons of a fingerprint like AB:CD:... are ignored.
+func decodeFingerprint(fingerprint string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.ReplaceAll(fingerprint, ":", ""))
+	if err != nil || len(b) != sha256.Size {
+		return nil, fmt.Errorf("invalid certificate fingerprint %q: expect a hex encoded SHA256 digest", fingerprint)
+	}
+	return b, nil
+}
+
+// applyEnv sets the address, credentials and CA certificate of the config from
+// the environment variables when the config has none of them.
+func applyEnv(cfg *Config) error {
+	var (
+		address  = os.Getenv(envURL)
+		cloudID  = os.Getenv(envCloudID)
+		username = os.Getenv(envUsername)
+		password = os.Getenv(envPassword)
+		apiKey   = os.Getenv(envAPIKey)
+		caCert   = os.Getenv(envCACert)
+	)
+
+	if len(cfg.Address) == 0 && len(cfg.Addresses) == 0 && len(cfg.CloudID) == 0 {
+		if len(address) > 0 && len(cloudID) > 0 {
+			return fmt.Errorf("cannot create client: both %s and %s are set", envURL, envCloudID)
+		}
+		cfg.Address, cfg.CloudID = address, cloudID
+	}
+
+	if len(cfg.Username) == 0 && len(cfg.Token) == 0 && len(cfg.APIKey) == 0 {
+		if len(username) > 0 && len(apiKey) > 0 {
+			return fmt.Errorf("cannot create client: both %s and %s are set", envUsername, envAPIKey)
+		}
+		if len(password) > 0 && len(username) == 0 {
+			return fmt.Errorf("cannot create client: %s is set without %s", envPassword, envUsername)
+		}
+		cfg.Username, cfg.Password, cfg.APIKey = username, password, apiKey
+	}
+
+	if cfg.CACert == nil && len(caCert) > 0 {
+		if strings.HasPrefix(strings.TrimSpace(caCert), "-----BEGIN") {
+			cfg.CACert = []byte(caCert)
+		} else {
+			pem, err := ioutil.ReadFile(caCert)
+			if err != nil {
+				return fmt.Errorf("cannot read %s: %s", envCACert, err)
+			}
+			cfg.CACert = pem
+		}
+	}
+
+	return nil
+}
 
+// newTransport creates the transport sending requests to the addresses of the config.
+func newTransport(cfg Config) (api.Transport, error) {
+	if len(cfg.Address) > 0 && len(cfg.Addresses) > 0 {
+		return nil, errors.New("cannot create client: both Address and Addresses are set")
+	}
+
+	if len(cfg.CloudID) > 0 && (len(cfg.Address) > 0 || len(cfg.Addresses) > 0) {
+		return nil, errors.New("cannot create client: both CloudID and Address are set")
+	}
+
+	if len(cfg.Address) == 0 && len(cfg.Addresses) == 0 && len(cfg.CloudID) == 0 {
+		return nil, fmt.Errorf("cannot create client: no address is set, set Address, Addresses or CloudID, or %s or %s", envURL, envCloudID)
+	}
+
+	addresses := cfg.Addresses
+	if len(cfg.CloudID) > 0 {
+		address, err := cloudIDToAddress(cfg.CloudID)
+		if err != nil {
+			return nil, err
+		}
+		addresses = []string{address}
+	} else if len(addresses) == 0 {
+		addresses = []string{cfg.Address}
+	}
+
+	urls, err := addrToUrls(addresses...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	urls, prefix, err := splitPathPrefix(urls)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(cfg.Token) > 0 && len(cfg.Username) > 0 {
+		return nil, errors.New("cannot create client: both Token and Username are set")
+	}
+
+	if len(cfg.APIKey) > 0 && len(cfg.Username) > 0 {
 		return nil, errors.New("cannot create client: both APIKey and Username are set")
 	}
 
+	if len(cfg.APIKey) > 0 && len(cfg.Token) > 0 {
+		return nil, errors.New("cannot create client: both APIKey and Token are set")
+	}
+
+	token := cfg.Token
+	if len(cfg.APIKey) > 0 {
+		token = cfg.APIKey
+	}
+
+	rt, err := newRoundTripper(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating transport: %s", err)
+	}
+
 	tp, err := elastictransport.New(elastictransport.Config{
 		URLs:         urls,
 		Username:     cfg.Username,
 		Password:     cfg.Password,
-		ServiceToken: cfg.Token,
+		ServiceToken: token,
+		DisableRetry: true,
+		Transport:    rt,
 	})
 
 	if err != nil {
 		return nil, fmt.Errorf("error creating transport: %s", err)
 	}
 
+	if len(prefix.Path) > 0 {
+		return pathPrefixTransport{Transport: tp, prefix: prefix}, nil
+	}
+
+	return tp, nil
+}
+
+// splitPathPrefix removes the path shared by the urls, so it can be prefixed by
+// pathPrefixTransport. The transport joins a path of the urls without keeping
+// the escaping of the request path, which turns an escaped '/' of an ID back to '/'.
+func splitPathPrefix(urls []*url.URL) ([]*url.URL, *url.URL, error) {
+	prefix := &url.URL{}
+	stripped := make([]*url.URL, 0, len(urls))
+	for i, u := range urls {
+		if i == 0 {
+			prefix.Path, prefix.RawPath = u.Path, u.RawPath
+		} else if u.EscapedPath() != prefix.EscapedPath() {
+			return nil, nil, errors.New("cannot create client: addresses have different paths")
+		}
+
+		c := *u
+		c.Path, c.RawPath = "", ""
+		stripped = append(stripped, &c)
+	}
+	return stripped, prefix, nil
+}
+
+// pathPrefixTransport prefixes the path of every request and keeps its escaping.
+type pathPrefixTransport struct {
+	api.Transport
+	prefix *url.URL
+}
+
+func (t pathPrefixTransport) Perform(req *http.Request) (*http.Response, error) {
+	escaped := req.URL.EscapedPath()
+	req.URL.Path = t.prefix.Path + req.URL.Path
+	req.URL.RawPath = t.prefix.EscapedPath() + escaped
+	return t.Transport.Perform(req)
+}
+
+// transportFields returns the names of the options which are set and only take effect on the transport built by NewClient.
+func transportFields(cfg Config) []string {
+	var fields []string
+	for name, set := range map[string]bool{
+		"Address":                len(cfg.Address) > 0,
+		"Addresses":              len(cfg.Addresses) > 0,
+		"CloudID":                len(cfg.CloudID) > 0,
+		"Username":               len(cfg.Username) > 0,
+		"Password":               len(cfg.Password) > 0,
+		"Token":                  len(cfg.Token) > 0,
+		"APIKey":                 len(cfg.APIKey) > 0,
+		"CACert":                 len(cfg.CACert) > 0,
+		"CertificateFingerprint": len(cfg.CertificateFingerprint) > 0,
+		"ClientCert":             len(cfg.ClientCert) > 0,
+		"ClientKey":              len(cfg.ClientKey) > 0,
+		"InsecureSkipVerify":     cfg.InsecureSkipVerify,
+		"Transport":              cfg.Transport != nil,
+	} {
+		if set {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// NewClientFromEnv creates a client configured by the environment variables.
+// see NewClient for the variables.
+func NewClientFromEnv() (*Client, error) {
+	return NewClient(Config{})
+}
+
+// NewClient creates a client, the options left unset fall back to the environment variables:
+//
+//	ENTERPRISE_SEARCH_URL       Address
+//	ENTERPRISE_SEARCH_CLOUD_ID  CloudID
+//	ENTERPRISE_SEARCH_USERNAME  Username
+//	ENTERPRISE_SEARCH_PASSWORD  Password
+//	ENTERPRISE_SEARCH_API_KEY   APIKey
+//	ENTERPRISE_SEARCH_CA_CERT   CACert, a path to the PEM file or the PEM itself
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.APITransport == nil {
+		if err := applyEnv(&cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	t := cfg.APITransport
+	if t != nil {
+		if fields := transportFields(cfg); len(fields) > 0 {
+			return nil, fmt.Errorf("cannot create client: APITransport is set along with %s", strings.Join(fields, ", "))
+		}
+	} else {
+		tp, err := newTransport(cfg)
+		if err != nil {
+			return nil, err
+		}
+		t = tp
+	}
+
+	if !cfg.DisableRetry {
+		t = api.WithRetry(t, api.RetryConfig{
+			MaxRetries:     cfg.MaxRetries,
+			RetryOnStatus:  cfg.RetryOnStatus,
+			RetryOnMethods: cfg.RetryOnMethods,
+			Backoff:        cfg.RetryBackoff,
+			MaxRetryAfter:  cfg.MaxRetryAfter,
+		})
+	}
+	if cfg.ReturnErrors {
+		t = api.WithErrors(t)
+	}
+
 	c := &Client{
 		EnterpriseSearch: EnterpriseSearch{
-			enterprise.New(tp),
+			enterprise.New(t),
 		},
 		AppSearch: AppSearch{
-			app.New(tp),
+			app.New(t),
 		},
 	}
 
@@ -1,12 +1,34 @@
 package jiangjing
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/nevill/jiangjing/api"
+	"github.com/nevill/jiangjing/api/app"
 )
 
 const (
@@ -131,6 +153,30 @@ func TestEngines(t *testing.T) {
 		}
 	})
 
+	t.Run("list all engines page by page", func(t *testing.T) {
+		it := client.AppSearch.Engines.List.All(
+			client.AppSearch.Engines.List.WithPage(1, 1),
+		)
+
+		found := false
+		for it.Next() {
+			var engine app.Engine
+			if err := it.Decode(&engine); err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+			if engine.Name == name {
+				found = true
+			}
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		if !found {
+			t.Fatalf("Expect to find engine %s.", name)
+		}
+	})
+
 	t.Run("get an engine", func(t *testing.T) {
 		resp, err := client.AppSearch.Engines.Get(name)
 		defer resp.Body.Close()
@@ -416,6 +462,8 @@ func TestSearch(t *testing.T) {
 		Results []Doc `json:"results"`
 	}
 
+	var docIds []string
+
 	t.Run("create new documents", func(t *testing.T) {
 		resp, err := client.AppSearch.Documents.Create(
 			engine,
@@ -457,6 +505,56 @@ func TestSearch(t *testing.T) {
 		if attempts == 5 && len(r.Results) == 0 {
 			t.Fatal("Max attempts reached, no records found")
 		}
+
+		for _, doc := range r.Results {
+			docIds = append(docIds, doc.Id)
+		}
+	})
+
+	t.Run("list all documents page by page", func(t *testing.T) {
+		it := client.AppSearch.Documents.List.All(
+			engine,
+			client.AppSearch.Documents.List.WithPage(1, 1),
+		)
+
+		var docs []Doc
+		for it.Next() {
+			var doc Doc
+			if err := it.Decode(&doc); err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+			docs = append(docs, doc)
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		if len(docs) != len(docIds) {
+			t.Fatalf("Expect to get %d documents, but got %d.", len(docIds), len(docs))
+		}
+	})
+
+	t.Run("get documents", func(t *testing.T) {
+		resp, err := client.AppSearch.Documents.Get(
+			engine,
+			client.AppSearch.Documents.Get.WithIds(docIds...),
+		)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r []Doc
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+
+		if len(r) != len(docIds) {
+			t.Fatalf("Expect to get %d documents, but got %d.", len(docIds), len(r))
+		}
 	})
 
 	t.Run("search for documents", func(t *testing.T) {
@@ -491,4 +589,1981 @@ func TestSearch(t *testing.T) {
 			t.Fatalf("Expect to get 1980, but got %s.", r.Results[0].Year.Raw)
 		}
 	})
+
+	t.Run("search with a typed query", func(t *testing.T) {
+		resp, err := client.AppSearch.Search(
+			client.AppSearch.Search.WithEngine(engine),
+			client.AppSearch.Search.WithQuery(app.SearchQuery{
+				Query:   "",
+				Filters: &app.Filter{All: []app.Filter{app.FilterValues("year", "1979", "1980")}},
+				Sort:    []app.Sort{{Field: "year", Order: "asc"}},
+				Page:    &app.Page{Size: 10},
+			}),
+		)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r SearchResult
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+
+		if len(r.Results) != 2 || r.Results[0].Name.Raw != "Galaxxian" {
+			t.Fatalf("Expect to get Galaxxian and Pack-Man, but got %v.", r.Results)
+		}
+	})
+
+	t.Run("decode a typed search response", func(t *testing.T) {
+		resp, err := client.AppSearch.Search(
+			client.AppSearch.Search.WithEngine(engine),
+			client.AppSearch.Search.WithQuery(app.SearchQuery{Query: "Pack-Man"}),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		r, err := app.DecodeSearchResponse(resp)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		if len(r.Meta.RequestID) == 0 {
+			t.Fatal("Expect to have a request id in meta.")
+		}
+		if len(r.Results) == 0 {
+			t.Fatal("Expect to get some results, but got nothing.")
+		}
+		if r.Results[0].Meta.Score <= 0 {
+			t.Fatalf("Expect to have a positive score, but got %f.", r.Results[0].Meta.Score)
+		}
+
+		var doc Doc
+		if err := r.Results[0].DecodeRaw(&doc); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if doc.Name != "Pack-Man" || doc.Id != r.Results[0].Meta.ID {
+			t.Fatalf("Expect to get Pack-Man, but got %v.", doc)
+		}
+	})
+
+	t.Run("iterate over all results", func(t *testing.T) {
+		for _, partition := range []string{"", "year"} {
+			it := client.AppSearch.Search.Iterator(context.Background(), engine, app.SearchQuery{
+				Page: &app.Page{Size: 1},
+			})
+			it.PartitionField = partition
+
+			ids := map[string]bool{}
+			for it.Next() {
+				ids[it.Result().Meta.ID] = true
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+			if len(ids) != len(docIds) {
+				t.Fatalf("Expect to get %d documents, but got %d.", len(docIds), len(ids))
+			}
+		}
+	})
+
+	t.Run("multi search for documents", func(t *testing.T) {
+		resp, err := client.AppSearch.MultiSearch(
+			client.AppSearch.MultiSearch.WithEngine(engine),
+			client.AppSearch.MultiSearch.WithBodies(
+				strings.NewReader(`{"query": "Pack-Man"}`),
+				strings.NewReader(`{"query": "Galaxxian"}`),
+			),
+		)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r []SearchResult
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+
+		if len(r) != 2 {
+			t.Fatalf("Expect to get 2 result sets, but got %d.", len(r))
+		}
+
+		if len(r[1].Results) == 0 || r[1].Results[0].Name.Raw != "Galaxxian" {
+			t.Fatalf("Expect to get Galaxxian in the second result set, but got %v.", r[1].Results)
+		}
+	})
+
+	t.Run("update documents", func(t *testing.T) {
+		resp, err := client.AppSearch.Documents.Update(
+			engine,
+			client.AppSearch.Documents.Update.WithDocuments(
+				map[string]interface{}{"id": docIds[0], "year": "2000"},
+			),
+		)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r []map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+
+		if len(r) != 1 || r[0]["id"] != docIds[0] {
+			t.Fatalf("Expect to get result of document %s, but got %v.", docIds[0], r)
+		}
+	})
+}
+
+func TestSchema(t *testing.T) {
+	client := newTestClient()
+	engine := "search-schema-testing"
+
+	{
+		// create a new engine for testing
+		if _, err := client.AppSearch.Engines.Create(engine); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	}
+	t.Cleanup(func() {
+		// remove the testing engine
+		if _, err := client.AppSearch.Engines.Delete(engine); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	})
+
+	t.Run("update schema", func(t *testing.T) {
+		resp, err := client.AppSearch.Schema.Update(engine, map[string]app.FieldType{
+			"price":    app.FieldTypeNumber,
+			"released": app.FieldTypeDate,
+		})
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+	})
+
+	t.Run("get schema", func(t *testing.T) {
+		resp, err := client.AppSearch.Schema.Get(engine)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r map[string]app.FieldType
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+
+		if r["price"] != app.FieldTypeNumber {
+			t.Fatalf("Expect to get field price as %s, but got %s.", app.FieldTypeNumber, r["price"])
+		}
+	})
+
+	t.Run("reject unknown field type", func(t *testing.T) {
+		_, err := client.AppSearch.Schema.Update(engine, map[string]app.FieldType{
+			"price": "money",
+		})
+		if err == nil {
+			t.Fatal("Expect to get an error for unknown field type.")
+		}
+	})
+}
+
+func TestCurations(t *testing.T) {
+	client := newTestClient()
+	engine := "search-curations-testing"
+
+	{
+		// create a new engine with documents for testing
+		if _, err := client.AppSearch.Engines.Create(engine); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if _, err := client.AppSearch.Documents.Create(
+			engine,
+			client.AppSearch.Documents.Create.WithDocuments(
+				map[string]interface{}{"id": "park-1", "name": "Yosemite"},
+				map[string]interface{}{"id": "park-2", "name": "Yellowstone"},
+			),
+		); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	}
+	t.Cleanup(func() {
+		// remove the testing engine
+		if _, err := client.AppSearch.Engines.Delete(engine); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	})
+
+	var curationId string
+
+	t.Run("create a curation", func(t *testing.T) {
+		resp, err := client.AppSearch.Curations.Create(
+			engine,
+			[]string{"park"},
+			client.AppSearch.Curations.Create.WithPromoted("park-1"),
+			client.AppSearch.Curations.Create.WithHidden("park-2"),
+		)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+
+		switch id := r["id"].(type) {
+		case string:
+			if !strings.HasPrefix(id, "cur-") {
+				t.Fatalf("Expect to have cur- as prefix in ID, but got: %s in response.", id)
+			}
+			curationId = id
+		default:
+			t.Fatal("Expect to have a field `id` returned in response.")
+		}
+	})
+
+	t.Run("update a curation", func(t *testing.T) {
+		resp, err := client.AppSearch.Curations.Update(
+			engine,
+			curationId,
+			[]string{"park", "parks"},
+			client.AppSearch.Curations.Update.WithPromoted("park-2"),
+		)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+	})
+
+	t.Run("get a curation", func(t *testing.T) {
+		resp, err := client.AppSearch.Curations.Get(engine, curationId)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r struct {
+			Queries  []string `json:"queries"`
+			Promoted []string `json:"promoted"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+
+		if len(r.Queries) != 2 {
+			t.Fatalf("Expect to have 2 queries, but got: %s.", r.Queries)
+		}
+		if len(r.Promoted) != 1 || r.Promoted[0] != "park-2" {
+			t.Fatalf("Expect to have park-2 promoted, but got: %s.", r.Promoted)
+		}
+	})
+
+	t.Run("list curations", func(t *testing.T) {
+		resp, err := client.AppSearch.Curations.List(
+			engine,
+			client.AppSearch.Curations.List.WithPage(1, 10),
+		)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r struct {
+			Results []map[string]interface{} `json:"results"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+
+		if len(r.Results) != 1 || r.Results[0]["id"] != curationId {
+			t.Fatalf("Expect to have curation %s returned in response, but got: %v.", curationId, r.Results)
+		}
+	})
+
+	t.Run("delete a curation", func(t *testing.T) {
+		resp, err := client.AppSearch.Curations.Delete(engine, curationId)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+	})
+}
+
+func TestMetaEngines(t *testing.T) {
+	client := newTestClient()
+	sources := []string{"search-meta-source-blue", "search-meta-source-green"}
+	meta := "search-meta-testing"
+
+	for _, engine := range sources {
+		if _, err := client.AppSearch.Engines.Create(engine); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	}
+	t.Cleanup(func() {
+		for _, engine := range append([]string{meta}, sources...) {
+			if _, err := client.AppSearch.Engines.Delete(engine); err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+		}
+	})
+
+	type Engine struct {
+		Name          string   `json:"name"`
+		Type          string   `json:"type"`
+		SourceEngines []string `json:"source_engines"`
+	}
+
+	t.Run("create a meta engine", func(t *testing.T) {
+		resp, err := client.AppSearch.Engines.Create(
+			meta,
+			client.AppSearch.Engines.Create.WithType(app.EngineTypeMeta),
+			client.AppSearch.Engines.Create.WithSourceEngines(sources[0]),
+		)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r Engine
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+		if r.Type != string(app.EngineTypeMeta) {
+			t.Fatalf("Expect to have type: %s, but got: %s in response.", app.EngineTypeMeta, r.Type)
+		}
+	})
+
+	t.Run("add source engines", func(t *testing.T) {
+		resp, err := client.AppSearch.Engines.AddSourceEngines(meta, sources[1:])
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r Engine
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+		if len(r.SourceEngines) != 2 {
+			t.Fatalf("Expect to have source engines: %s, but got: %s in response.", sources, r.SourceEngines)
+		}
+	})
+
+	t.Run("remove source engines", func(t *testing.T) {
+		resp, err := client.AppSearch.Engines.RemoveSourceEngines(meta, sources[:1])
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r Engine
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+		if len(r.SourceEngines) != 1 || r.SourceEngines[0] != sources[1] {
+			t.Fatalf("Expect to have source engines: %s, but got: %s in response.", sources[1:], r.SourceEngines)
+		}
+	})
+}
+
+func TestEnginesCreateOptions(t *testing.T) {
+	client := newTestClient()
+	name := "search-engine-language-testing"
+
+	t.Cleanup(func() {
+		if _, err := client.AppSearch.Engines.Delete(name); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	})
+
+	t.Run("create an engine with language", func(t *testing.T) {
+		resp, err := client.AppSearch.Engines.Create(
+			name,
+			client.AppSearch.Engines.Create.WithLanguage("en"),
+			client.AppSearch.Engines.Create.WithNumberOfShards(2),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		engine, err := app.DecodeEngine(resp)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if engine.Name != name {
+			t.Fatalf("Expect to have: %s, but got: %s in response.", name, engine.Name)
+		}
+		if engine.Language == nil || *engine.Language != "en" {
+			t.Fatalf("Expect to have language en, but got: %v in response.", engine.Language)
+		}
+	})
+}
+
+func TestEnginesCreateBody(t *testing.T) {
+	var bodies []map[string]interface{}
+	engines := app.New(transportFunc(func(req *http.Request) (*http.Response, error) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, body)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(`{}`)),
+		}, nil
+	})).Engines
+
+	t.Run("send language and number of shards", func(t *testing.T) {
+		bodies = nil
+		resp, err := engines.Create(
+			"national-parks",
+			engines.Create.WithLanguage("en"),
+			engines.Create.WithNumberOfShards(2),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		resp.Body.Close()
+
+		body, _ := json.Marshal(bodies)
+		expected := `[{"index_create_settings_override":{"number_of_shards":2},"language":"en","name":"national-parks"}]`
+		if string(body) != expected {
+			t.Fatalf("Expect to send: %s, but got: %s.", expected, body)
+		}
+	})
+
+	t.Run("send an elasticsearch index", func(t *testing.T) {
+		bodies = nil
+		resp, err := engines.Create(
+			"national-parks",
+			engines.Create.WithElasticsearchIndex("search-national-parks"),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		resp.Body.Close()
+
+		body, _ := json.Marshal(bodies)
+		expected := `[{"name":"national-parks","search_index":{"index_name":"search-national-parks","type":"elasticsearch"}}]`
+		if string(body) != expected {
+			t.Fatalf("Expect to send: %s, but got: %s.", expected, body)
+		}
+	})
+
+	t.Run("reject invalid options", func(t *testing.T) {
+		bodies = nil
+		for name, option := range map[string]func(*app.EnginesCreateRequest){
+			"unsupported language": engines.Create.WithLanguage("tlh"),
+			"index without prefix": engines.Create.WithElasticsearchIndex("national-parks"),
+		} {
+			if _, err := engines.Create("national-parks", option); err == nil {
+				t.Fatalf("Expect to get an error for %s.", name)
+			}
+		}
+		if len(bodies) != 0 {
+			t.Fatalf("Expect to send no request, but sent %d.", len(bodies))
+		}
+	})
+}
+
+func TestSearchSettings(t *testing.T) {
+	client := newTestClient()
+	engine := "search-settings-testing"
+
+	{
+		// create a new engine with schema for testing
+		if _, err := client.AppSearch.Engines.Create(engine); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if _, err := client.AppSearch.Schema.Update(engine, map[string]app.FieldType{
+			"name":  app.FieldTypeText,
+			"title": app.FieldTypeText,
+			"rank":  app.FieldTypeNumber,
+		}); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	}
+	t.Cleanup(func() {
+		// remove the testing engine
+		if _, err := client.AppSearch.Engines.Delete(engine); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	})
+
+	t.Run("update search settings", func(t *testing.T) {
+		resp, err := client.AppSearch.SearchSettings.Update(engine, app.RelevanceTuning{
+			SearchFields: map[string]app.SearchField{
+				"name":  {Weight: 3},
+				"title": {Weight: 1},
+			},
+			Boosts: map[string][]app.Boost{
+				"rank": {
+					{Type: app.BoostTypeFunctional, Function: "logarithmic", Operation: "multiply", Factor: 2},
+				},
+			},
+			ResultFields: map[string]app.ResultField{
+				"name": {Raw: &app.RawField{}, Snippet: &app.SnippetField{Size: 20, Fallback: true}},
+			},
+		})
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+	})
+
+	t.Run("get search settings", func(t *testing.T) {
+		resp, err := client.AppSearch.SearchSettings.Get(engine)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r app.RelevanceTuning
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+		if r.SearchFields["name"].Weight != 3 {
+			t.Fatalf("Expect to have weight 3 for field name, but got: %v.", r.SearchFields["name"])
+		}
+		if len(r.Boosts["rank"]) != 1 {
+			t.Fatalf("Expect to have a boost for field rank, but got: %v.", r.Boosts)
+		}
+	})
+
+	t.Run("reset search settings", func(t *testing.T) {
+		resp, err := client.AppSearch.SearchSettings.Reset(engine)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r app.RelevanceTuning
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+		if len(r.Boosts) != 0 {
+			t.Fatalf("Expect to have no boosts after reset, but got: %v.", r.Boosts)
+		}
+	})
+}
+
+func TestCredentials(t *testing.T) {
+	client := newTestClient()
+	name := "search-credentials-testing"
+
+	t.Run("create a private key", func(t *testing.T) {
+		resp, err := client.AppSearch.Credentials.Create(app.Credential{
+			Name:             name,
+			Type:             app.KeyTypePrivate,
+			Read:             true,
+			Write:            true,
+			AccessAllEngines: true,
+		})
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r app.Credential
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+		if !strings.HasPrefix(r.Key, "private-") {
+			t.Fatalf("Expect to have private- as prefix in key, but got: %s in response.", r.Key)
+		}
+	})
+
+	t.Run("update a key", func(t *testing.T) {
+		resp, err := client.AppSearch.Credentials.Update(name, app.Credential{
+			Type:             app.KeyTypePrivate,
+			Read:             true,
+			AccessAllEngines: true,
+		})
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+	})
+
+	t.Run("get a key", func(t *testing.T) {
+		resp, err := client.AppSearch.Credentials.Get(name)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+
+		var r app.Credential
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatalf("Error parsing the response body: %s", err)
+		}
+		if r.Write {
+			t.Fatal("Expect to have a read only key.")
+		}
+	})
+
+	t.Run("list keys", func(t *testing.T) {
+		resp, err := client.AppSearch.Credentials.List(
+			client.AppSearch.Credentials.List.WithPage(1, 25),
+		)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+	})
+
+	t.Run("delete a key", func(t *testing.T) {
+		resp, err := client.AppSearch.Credentials.Delete(name)
+		defer resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+	})
+}
+
+func TestSignedSearchKey(t *testing.T) {
+	searchKey := "search-soaewu2ye6uc45dr8mcd54v8"
+	keyName := "search-key"
+
+	signed, err := app.NewSignedSearchKey(searchKey, keyName, app.SearchKeyRestrictions{
+		SearchFields: map[string]app.SearchField{"name": {}},
+		ResultFields: map[string]app.ResultField{"name": {Raw: &app.RawField{}}},
+		Filters:      map[string]interface{}{"year": "1980"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	t.Run("verify a signed key", func(t *testing.T) {
+		key, err := app.VerifySignedSearchKey(signed, searchKey)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if key.APIKeyName != keyName {
+			t.Fatalf("Expect to have key name: %s, but got: %s.", keyName, key.APIKeyName)
+		}
+		if _, ok := key.SearchFields["name"]; !ok {
+			t.Fatalf("Expect to have search field name, but got: %v.", key.SearchFields)
+		}
+	})
+
+	t.Run("reject a key signed by another search key", func(t *testing.T) {
+		if _, err := app.VerifySignedSearchKey(signed, "search-another"); err == nil {
+			t.Fatal("Expect to get an error for signature mismatch.")
+		}
+	})
+
+	t.Run("reject signing with a private key", func(t *testing.T) {
+		if _, err := app.NewSignedSearchKey("private-xxx", keyName, app.SearchKeyRestrictions{}); err == nil {
+			t.Fatal("Expect to get an error for signing with a private key.")
+		}
+	})
+}
+
+func TestSearchQuery(t *testing.T) {
+	query := app.SearchQuery{
+		Query: "park",
+		Filters: &app.Filter{
+			All: []app.Filter{
+				app.FilterValues("states", "California"),
+				app.AnyOf(
+					app.FilterRange("acres", 1000, nil),
+					app.FilterGeo("location", "37.386483, -122.083842", 300, "km"),
+				),
+			},
+			None: []app.Filter{app.FilterValues("world_heritage_site", "false")},
+		},
+		Facets: map[string][]app.Facet{
+			"states": {{Type: "value", Size: 5}},
+		},
+		Sort: []app.Sort{{Field: "_score", Order: "desc"}},
+		Page: &app.Page{Current: 2, Size: 20},
+		ResultFields: map[string]app.ResultField{
+			"title": {Snippet: &app.SnippetField{Size: 50, Fallback: true}},
+		},
+		Analytics: &app.Analytics{Tags: []string{"web"}},
+	}
+
+	body, err := json.Marshal(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	expected := `{"query":"park",` +
+		`"filters":{"all":[{"states":"California"},{"any":[{"acres":{"from":1000}},` +
+		`{"location":{"center":"37.386483, -122.083842","distance":300,"unit":"km"}}]}],` +
+		`"none":[{"world_heritage_site":"false"}]},` +
+		`"facets":{"states":[{"type":"value","size":5}]},` +
+		`"result_fields":{"title":{"snippet":{"size":50,"fallback":true}}},` +
+		`"sort":[{"_score":"desc"}],` +
+		`"page":{"current":2,"size":20},` +
+		`"analytics":{"tags":["web"]}}`
+	if string(body) != expected {
+		t.Fatalf("Expect to get: %s, but got: %s.", expected, body)
+	}
+}
+
+func TestSearchResponseGroup(t *testing.T) {
+	body := `{"meta":{"page":{"current":1,"size":10,"total_pages":1,"total_results":1}},"results":[` +
+		`{"title":{"raw":"Yosemite"},"_meta":{"id":"park_yosemite","score":2},` +
+		`"_group":[{"title":{"raw":"Sequoia"},"_meta":{"id":"park_sequoia","score":1}}]}]}`
+	resp := &api.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+
+	r, err := app.DecodeSearchResponse(resp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	result := r.Results[0]
+	if _, ok := result.Fields["_group"]; ok {
+		t.Fatal("Expect _group not to be decoded as a field.")
+	}
+	if len(result.Group) != 1 {
+		t.Fatalf("Expect to get 1 grouped result, but got %d.", len(result.Group))
+	}
+	if id := result.Group[0].Meta.ID; id != "park_sequoia" {
+		t.Fatalf("Expect to get grouped result park_sequoia, but got %s.", id)
+	}
+	if title := string(result.Group[0].Fields["title"].Raw); title != `"Sequoia"` {
+		t.Fatalf("Expect to get title \"Sequoia\", but got %s.", title)
+	}
+}
+
+func TestBulkIndexer(t *testing.T) {
+	client := newTestClient()
+	engine := "search-bulk-indexer-testing"
+
+	{
+		// create a new engine for testing
+		if _, err := client.AppSearch.Engines.Create(engine); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	}
+	t.Cleanup(func() {
+		// remove the testing engine
+		if _, err := client.AppSearch.Engines.Delete(engine); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	})
+
+	bi, err := app.NewBulkIndexer(app.BulkIndexerConfig{
+		Client:         client.AppSearch.API,
+		Engine:         engine,
+		NumWorkers:     2,
+		FlushDocuments: 10,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	var failed uint64
+	count := 250
+	for i := 0; i < count; i++ {
+		err := bi.Add(context.Background(), app.BulkIndexerItem{
+			Document: map[string]interface{}{"id": fmt.Sprintf("doc-%d", i), "name": fmt.Sprintf("Document %d", i)},
+			OnFailure: func(ctx context.Context, item app.BulkIndexerItem, res app.DocumentResult, err error) {
+				atomic.AddUint64(&failed, 1)
+			},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	}
+
+	if err := bi.Close(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	stats := bi.Stats()
+	if stats.NumAdded != uint64(count) || stats.NumIndexed != uint64(count) {
+		t.Fatalf("Expect to index %d documents, but got %+v.", count, stats)
+	}
+	if stats.NumRequests < uint64(count/10) {
+		t.Fatalf("Expect to send at least %d requests, but got %d.", count/10, stats.NumRequests)
+	}
+	if failed != 0 {
+		t.Fatalf("Expect no failed documents, but got %d.", failed)
+	}
+
+	if err := bi.Add(context.Background(), app.BulkIndexerItem{}); err != app.ErrBulkIndexerClosed {
+		t.Fatalf("Expect to get %s, but got %v.", app.ErrBulkIndexerClosed, err)
+	}
+}
+
+func TestDocumentsResults(t *testing.T) {
+	client := newTestClient()
+	engine := "search-documents-results-testing"
+
+	{
+		// create a new engine for testing
+		if _, err := client.AppSearch.Engines.Create(engine); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	}
+	t.Cleanup(func() {
+		// remove the testing engine
+		if _, err := client.AppSearch.Engines.Delete(engine); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	})
+
+	t.Run("decode results of created documents", func(t *testing.T) {
+		resp, err := client.AppSearch.Documents.Create(
+			engine,
+			client.AppSearch.Documents.Create.WithDocuments(
+				map[string]interface{}{"id": "good", "name": "Yosemite"},
+				map[string]interface{}{"id": "bad", "Invalid Field": "Yellowstone"},
+			),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		results, err := app.DecodeDocumentsResponse(resp)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if len(results) != 2 || len(results[0].Errors) != 0 || len(results[1].Errors) == 0 {
+			t.Fatalf("Expect the second document to fail, but got %v.", results)
+		}
+	})
+
+	t.Run("fail on partial errors", func(t *testing.T) {
+		resp, err := client.AppSearch.Documents.Create(
+			engine,
+			client.AppSearch.Documents.Create.WithDocuments(
+				map[string]interface{}{"id": "bad", "Invalid Field": "Yellowstone"},
+			),
+			client.AppSearch.Documents.Create.WithFailOnPartial(),
+		)
+		defer resp.Body.Close()
+
+		var derr *app.DocumentsError
+		if !errors.As(err, &derr) {
+			t.Fatalf("Expect to get a DocumentsError, but got %v.", err)
+		}
+		if len(derr.Failed) != 1 || derr.Failed[0].ID != "bad" {
+			t.Fatalf("Expect document bad to fail, but got %v.", derr.Failed)
+		}
+	})
+
+	t.Run("decode results of deleted documents", func(t *testing.T) {
+		resp, err := client.AppSearch.Documents.Delete(
+			engine,
+			client.AppSearch.Documents.Delete.WithIds("good", "missing"),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		results, err := app.DecodeDocumentsDeleteResponse(resp)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if len(results) != 2 || !results[0].Deleted || results[1].Deleted {
+			t.Fatalf("Expect to delete only document good, but got %v.", results)
+		}
+	})
+}
+
+func TestErrors(t *testing.T) {
+	engine := "search-missing-engine"
+
+	t.Run("decode an error response", func(t *testing.T) {
+		client := newTestClient()
+		resp, err := client.AppSearch.Engines.Get(engine)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		_, err = app.DecodeEngine(resp)
+		if !errors.Is(err, api.ErrNotFound) {
+			t.Fatalf("Expect to get %s, but got %v.", api.ErrNotFound, err)
+		}
+	})
+
+	t.Run("return errors for non-2xx responses", func(t *testing.T) {
+		client, err := NewClient(Config{
+			Address:      address,
+			Username:     username,
+			Password:     password,
+			ReturnErrors: true,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		_, err = client.AppSearch.Engines.Get(engine)
+		var apiErr *api.Error
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Expect to get an api.Error, but got %v.", err)
+		}
+		if apiErr.StatusCode != http.StatusNotFound || len(apiErr.Messages) == 0 {
+			t.Fatalf("Expect to get a not found error with messages, but got %v.", apiErr)
+		}
+		if errors.Is(err, api.ErrConflict) {
+			t.Fatalf("Expect not to be %s.", api.ErrConflict)
+		}
+	})
+}
+
+func TestPaths(t *testing.T) {
+	t.Run("escape path segments", func(t *testing.T) {
+		var paths []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.EscapedPath())
+			w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		for prefix, expected := range map[string]string{
+			"":        "/api/as/v1/engines/national-parks/synonyms/syn%2F1%3Fa%23b",
+			"/ent":    "/ent/api/as/v1/engines/national-parks/synonyms/syn%2F1%3Fa%23b",
+			"/a%2Fb/": "/a%2Fb/api/as/v1/engines/national-parks/synonyms/syn%2F1%3Fa%23b",
+		} {
+			client, err := NewClient(Config{Address: server.URL + prefix, DisableRetry: true})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+
+			paths = nil
+			resp, err := client.AppSearch.Synonyms.Get("national-parks", "syn/1?a#b")
+			if err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+			resp.Body.Close()
+			if len(paths) != 1 || paths[0] != expected {
+				t.Fatalf("Expect to request: %s, but got: %v.", expected, paths)
+			}
+		}
+	})
+
+	t.Run("reject addresses with different paths", func(t *testing.T) {
+		_, err := NewClient(Config{Addresses: []string{"http://localhost:3002/a", "http://localhost:3003/b"}})
+		if err == nil {
+			t.Fatal("Expect to get an error for addresses with different paths.")
+		}
+	})
+
+	t.Run("reject invalid engine names", func(t *testing.T) {
+		client := newTestClient()
+		for _, name := range []string{"", "National Parks", "parks/../credentials"} {
+			if _, err := client.AppSearch.Synonyms.List(name); err == nil {
+				t.Fatalf("Expect to get an error for engine name %q.", name)
+			}
+		}
+	})
+}
+
+func TestRetry(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"results": []}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Address:      server.URL,
+		RetryBackoff: func(int) time.Duration { return time.Hour },
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	t.Run("retry and replay the body", func(t *testing.T) {
+		query := `{"query": "park"}`
+		resp, err := client.AppSearch.Search(
+			client.AppSearch.Search.WithEngine("national-parks"),
+			client.AppSearch.Search.WithBody(io.MultiReader(strings.NewReader(query))),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expect to get status: %d, but got: %d\n", http.StatusOK, resp.StatusCode)
+		}
+		if len(bodies) != 3 {
+			t.Fatalf("Expect to send 3 requests, but sent %d.", len(bodies))
+		}
+		for _, body := range bodies {
+			if body != query {
+				t.Fatalf("Expect to send body: %s, but got: %s.", query, body)
+			}
+		}
+	})
+
+	t.Run("override retries of a request", func(t *testing.T) {
+		bodies = nil
+		ctx := api.ContextWithRetry(context.Background(), api.RetryConfig{Disable: true})
+		resp, err := client.AppSearch.Engines.Get(
+			"national-parks",
+			client.AppSearch.Engines.Get.WithContext(ctx),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests || len(bodies) != 1 {
+			t.Fatalf("Expect to get status %d after one request, but got %d after %d.", http.StatusTooManyRequests, resp.StatusCode, len(bodies))
+		}
+	})
+}
+
+func TestAddresses(t *testing.T) {
+	hits := make([]int, 2)
+	var servers []*httptest.Server
+	for i := range hits {
+		i := i
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[i]++
+			w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+		servers = append(servers, server)
+	}
+
+	client, err := NewClient(Config{
+		Addresses: []string{servers[0].URL, servers[1].URL},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	t.Run("balance requests between nodes", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			resp, err := client.Health()
+			if err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+			resp.Body.Close()
+		}
+		if hits[0] != 2 || hits[1] != 2 {
+			t.Fatalf("Expect to send 2 requests to each node, but got %v.", hits)
+		}
+	})
+
+	t.Run("skip a dead node", func(t *testing.T) {
+		servers[0].Close()
+		for i := 0; i < 4; i++ {
+			resp, err := client.Health()
+			if err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+			resp.Body.Close()
+		}
+		if hits[1] != 6 {
+			t.Fatalf("Expect to send all requests to the live node, but got %v.", hits)
+		}
+	})
+
+	t.Run("reject invalid addresses", func(t *testing.T) {
+		for _, cfg := range []Config{
+			{Addresses: []string{servers[1].URL, "localhost:3002"}},
+			{Address: "ftp://localhost:3002"},
+			{Address: servers[1].URL, Addresses: []string{servers[1].URL}},
+		} {
+			if _, err := NewClient(cfg); err == nil {
+				t.Fatalf("Expect to get an error for %+v.", cfg)
+			}
+		}
+	})
+}
+
+func TestTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cert := server.Certificate()
+	digest := sha256.Sum256(cert.Raw)
+
+	for name, cfg := range map[string]Config{
+		"trust a CA certificate": {
+			CACert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+		},
+		"pin a certificate fingerprint": {
+			CertificateFingerprint: hex.EncodeToString(digest[:]),
+		},
+		"skip verifying the certificate": {
+			InsecureSkipVerify: true,
+		},
+	} {
+		cfg := cfg
+		t.Run(name, func(t *testing.T) {
+			cfg.Address = server.URL
+			cfg.DisableRetry = true
+			client, err := NewClient(cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+
+			resp, err := client.Health()
+			if err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+			resp.Body.Close()
+		})
+	}
+
+	t.Run("reject an unknown certificate", func(t *testing.T) {
+		client, err := NewClient(Config{Address: server.URL, DisableRetry: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if _, err := client.Health(); err == nil {
+			t.Fatal("Expect to get an error for an unknown certificate.")
+		}
+	})
+
+	t.Run("reject an invalid fingerprint", func(t *testing.T) {
+		if _, err := NewClient(Config{Address: server.URL, CertificateFingerprint: "AB:CD"}); err == nil {
+			t.Fatal("Expect to get an error for an invalid fingerprint.")
+		}
+	})
+
+	t.Run("reject a mismatched fingerprint", func(t *testing.T) {
+		other := sha256.Sum256([]byte("other"))
+		client, err := NewClient(Config{
+			Address:                server.URL,
+			CertificateFingerprint: hex.EncodeToString(other[:]),
+			DisableRetry:           true,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if _, err := client.Health(); err == nil {
+			t.Fatal("Expect to get an error for a mismatched fingerprint.")
+		}
+	})
+
+	t.Run("send a client certificate with a pinned fingerprint", func(t *testing.T) {
+		var peers int32
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.StoreInt32(&peers, int32(len(r.TLS.PeerCertificates)))
+			w.Write([]byte(`{}`))
+		}))
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+		server.StartTLS()
+		defer server.Close()
+
+		digest := sha256.Sum256(server.Certificate().Raw)
+		clientCert, clientKey := newTestCertificate(t)
+
+		client, err := NewClient(Config{
+			Address:                server.URL,
+			CertificateFingerprint: strings.ToUpper(hex.EncodeToString(digest[:])),
+			ClientCert:             clientCert,
+			ClientKey:              clientKey,
+			DisableRetry:           true,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		resp, err := client.Health()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		resp.Body.Close()
+
+		if n := atomic.LoadInt32(&peers); n != 1 {
+			t.Fatalf("Expect the server to get 1 client certificate, but got %d.", n)
+		}
+	})
+}
+
+// newTestCertificate returns a PEM encoded self-signed certificate and its key.
+func newTestCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type transportFunc func(*http.Request) (*http.Response, error)
+
+func (f transportFunc) Perform(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport(t *testing.T) {
+	respond := func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(`{}`)),
+			Request:    req,
+		}, nil
+	}
+
+	t.Run("send requests with a custom round tripper", func(t *testing.T) {
+		var paths []string
+		client, err := NewClient(Config{
+			Address: address,
+			Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				paths = append(paths, req.URL.Path)
+				return respond(req)
+			}),
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		resp, err := client.AppSearch.Engines.Get("national-parks")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		resp.Body.Close()
+		if len(paths) != 1 || paths[0] != "/api/as/v1/engines/national-parks" {
+			t.Fatalf("Expect to send a request for the engine, but got %v.", paths)
+		}
+	})
+
+	t.Run("send requests with a custom api transport", func(t *testing.T) {
+		var paths []string
+		client, err := NewClient(Config{
+			APITransport: transportFunc(func(req *http.Request) (*http.Response, error) {
+				paths = append(paths, req.URL.Path)
+				return respond(req)
+			}),
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		resp, err := client.Health()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		resp.Body.Close()
+		if len(paths) != 1 || paths[0] != "/api/ent/v1/internal/health" {
+			t.Fatalf("Expect to send a health request, but got %v.", paths)
+		}
+	})
+
+	t.Run("reject options ignored by a custom api transport", func(t *testing.T) {
+		for _, cfg := range []Config{
+			{Address: address},
+			{Transport: roundTripperFunc(respond)},
+			{APIKey: "private-xxx"},
+			{Username: username, Password: password},
+			{Token: "token"},
+			{CACert: []byte("cert")},
+			{CertificateFingerprint: "AB:CD"},
+			{ClientCert: []byte("cert"), ClientKey: []byte("key")},
+			{InsecureSkipVerify: true},
+		} {
+			cfg.APITransport = transportFunc(respond)
+			if _, err := NewClient(cfg); err == nil {
+				t.Fatalf("Expect to get an error for %+v.", cfg)
+			}
+		}
+	})
+
+	t.Run("reject TLS options for a custom round tripper", func(t *testing.T) {
+		_, err := NewClient(Config{
+			Address:            address,
+			Transport:          roundTripperFunc(respond),
+			InsecureSkipVerify: true,
+		})
+		if err == nil {
+			t.Fatal("Expect to get an error for TLS options with a custom round tripper.")
+		}
+	})
+}
+
+func TestCloudID(t *testing.T) {
+	encode := func(s string) string {
+		return "deployment:" + base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	t.Run("decode the enterprise search address", func(t *testing.T) {
+		for cloudID, expected := range map[string]string{
+			encode("us-central1.gcp.cloud.es.io$es-uuid$kb-uuid$ent-uuid"):      "https://ent-uuid.us-central1.gcp.cloud.es.io:443",
+			encode("us-central1.gcp.cloud.es.io:9243$es-uuid$kb-uuid$ent-uuid"): "https://ent-uuid.us-central1.gcp.cloud.es.io:9243",
+			encode("us-central1.gcp.cloud.es.io$es-uuid$kb-uuid$ent-uuid:8443"): "https://ent-uuid.us-central1.gcp.cloud.es.io:8443",
+		} {
+			address, err := cloudIDToAddress(cloudID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+			if address != expected {
+				t.Fatalf("Expect to get: %s, but got: %s.", expected, address)
+			}
+		}
+	})
+
+	t.Run("reject a cloud id without enterprise search", func(t *testing.T) {
+		if _, err := NewClient(Config{CloudID: encode("us-central1.gcp.cloud.es.io$es-uuid$kb-uuid")}); err == nil {
+			t.Fatal("Expect to get an error for a cloud id without enterprise search.")
+		}
+	})
+
+	t.Run("reject both cloud id and address", func(t *testing.T) {
+		cloudID := encode("us-central1.gcp.cloud.es.io$es-uuid$kb-uuid$ent-uuid")
+		if _, err := NewClient(Config{CloudID: cloudID, Address: address}); err == nil {
+			t.Fatal("Expect to get an error for both cloud id and address.")
+		}
+	})
+}
+
+func TestAPIKey(t *testing.T) {
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Address: server.URL,
+		APIKey:  "private-xxxxxxxxxxxxxxxxxxxxxxxx",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	t.Run("authenticate with the key of the client", func(t *testing.T) {
+		resp, err := client.AppSearch.Engines.Get("national-parks")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		resp.Body.Close()
+		if expected := "Bearer private-xxxxxxxxxxxxxxxxxxxxxxxx"; auth[len(auth)-1] != expected {
+			t.Fatalf("Expect to send: %s, but got: %s.", expected, auth[len(auth)-1])
+		}
+	})
+
+	t.Run("authenticate with the key of a request", func(t *testing.T) {
+		resp, err := client.AppSearch.Search(
+			client.AppSearch.Search.WithAuth("search-xxxxxxxxxxxxxxxxxxxxxxxx"),
+			client.AppSearch.Search.WithEngine("national-parks"),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		resp.Body.Close()
+		if expected := "Bearer search-xxxxxxxxxxxxxxxxxxxxxxxx"; auth[len(auth)-1] != expected {
+			t.Fatalf("Expect to send: %s, but got: %s.", expected, auth[len(auth)-1])
+		}
+	})
+
+	t.Run("authenticate with the key of a request on a custom transport", func(t *testing.T) {
+		var got string
+		appAPI := app.New(transportFunc(func(req *http.Request) (*http.Response, error) {
+			got = req.Header.Get("Authorization")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(`{}`)),
+			}, nil
+		}))
+
+		resp, err := appAPI.Engines.Get("national-parks", appAPI.Engines.Get.WithAuth("search-xxxxxxxxxxxxxxxxxxxxxxxx"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		resp.Body.Close()
+		if expected := "Bearer search-xxxxxxxxxxxxxxxxxxxxxxxx"; got != expected {
+			t.Fatalf("Expect to send: %s, but got: %s.", expected, got)
+		}
+	})
+
+	t.Run("iterate search results with the key of a request", func(t *testing.T) {
+		var got string
+		appAPI := app.New(transportFunc(func(req *http.Request) (*http.Response, error) {
+			got = req.Header.Get("Authorization")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body: ioutil.NopCloser(strings.NewReader(
+					`{"meta":{"page":{"current":1,"size":1,"total_pages":1,"total_results":1}},` +
+						`"results":[{"_meta":{"id":"park_yosemite","score":1}}]}`)),
+			}, nil
+		}))
+
+		it := appAPI.Search.Iterator(context.Background(), "national-parks", app.SearchQuery{},
+			appAPI.Search.WithAuth("search-xxxxxxxxxxxxxxxxxxxxxxxx"))
+		for it.Next() {
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if expected := "Bearer search-xxxxxxxxxxxxxxxxxxxxxxxx"; got != expected {
+			t.Fatalf("Expect to send: %s, but got: %s.", expected, got)
+		}
+	})
+
+	t.Run("index documents with the key of a bulk indexer", func(t *testing.T) {
+		var got string
+		appAPI := app.New(transportFunc(func(req *http.Request) (*http.Response, error) {
+			got = req.Header.Get("Authorization")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(`[{"id":"park_yosemite","errors":[]}]`)),
+			}, nil
+		}))
+
+		bi, err := app.NewBulkIndexer(app.BulkIndexerConfig{
+			Client:     appAPI,
+			Engine:     "national-parks",
+			Auth:       "private-yyyyyyyyyyyyyyyyyyyyyyyy",
+			NumWorkers: 1,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if err := bi.Add(context.Background(), app.BulkIndexerItem{
+			Document: map[string]interface{}{"id": "park_yosemite"},
+		}); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if err := bi.Close(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		if stats := bi.Stats(); stats.NumIndexed != 1 {
+			t.Fatalf("Expect 1 document indexed, but got %+v.", stats)
+		}
+		if expected := "Bearer private-yyyyyyyyyyyyyyyyyyyyyyyy"; got != expected {
+			t.Fatalf("Expect to send: %s, but got: %s.", expected, got)
+		}
+	})
+
+	t.Run("reject conflicting credentials", func(t *testing.T) {
+		for _, cfg := range []Config{
+			{Address: server.URL, APIKey: "private-xxx", Username: username},
+			{Address: server.URL, APIKey: "private-xxx", Token: "token"},
+		} {
+			if _, err := NewClient(cfg); err == nil {
+				t.Fatalf("Expect to get an error for %+v.", cfg)
+			}
+		}
+	})
+}
+
+func setenv(t *testing.T, env map[string]string) {
+	for key, value := range env {
+		old, ok := os.LookupEnv(key)
+		os.Setenv(key, value)
+		key := key
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(key, old)
+			} else {
+				os.Unsetenv(key)
+			}
+		})
+	}
+}
+
+func TestClientFromEnv(t *testing.T) {
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	t.Run("create a client from the environment", func(t *testing.T) {
+		setenv(t, map[string]string{
+			"ENTERPRISE_SEARCH_URL":     server.URL,
+			"ENTERPRISE_SEARCH_API_KEY": "private-xxxxxxxxxxxxxxxxxxxxxxxx",
+		})
+
+		client, err := NewClientFromEnv()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+
+		resp, err := client.Health()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		resp.Body.Close()
+		if expected := "Bearer private-xxxxxxxxxxxxxxxxxxxxxxxx"; len(auth) != 1 || auth[0] != expected {
+			t.Fatalf("Expect to send: %s, but got: %v.", expected, auth)
+		}
+	})
+
+	t.Run("prefer the config over the environment", func(t *testing.T) {
+		setenv(t, map[string]string{
+			"ENTERPRISE_SEARCH_URL":      "ftp://localhost",
+			"ENTERPRISE_SEARCH_USERNAME": "nobody",
+		})
+
+		if _, err := NewClient(Config{Address: server.URL, APIKey: "private-xxx"}); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+	})
+
+	t.Run("reject a missing address", func(t *testing.T) {
+		for _, key := range []string{"ENTERPRISE_SEARCH_URL", "ENTERPRISE_SEARCH_CLOUD_ID"} {
+			setenv(t, map[string]string{key: ""})
+		}
+
+		_, err := NewClientFromEnv()
+		if err == nil || !strings.Contains(err.Error(), "ENTERPRISE_SEARCH_URL") {
+			t.Fatalf("Expect to get an error naming ENTERPRISE_SEARCH_URL, but got %v.", err)
+		}
+	})
+
+	t.Run("reject conflicting variables", func(t *testing.T) {
+		for _, env := range []map[string]string{
+			{"ENTERPRISE_SEARCH_URL": server.URL, "ENTERPRISE_SEARCH_CLOUD_ID": "deployment:xxx"},
+			{"ENTERPRISE_SEARCH_URL": server.URL, "ENTERPRISE_SEARCH_USERNAME": username, "ENTERPRISE_SEARCH_API_KEY": "private-xxx"},
+			{"ENTERPRISE_SEARCH_URL": server.URL, "ENTERPRISE_SEARCH_PASSWORD": password},
+			{"ENTERPRISE_SEARCH_URL": server.URL, "ENTERPRISE_SEARCH_CA_CERT": "/nonexistent/ca.crt"},
+		} {
+			t.Run("", func(t *testing.T) {
+				setenv(t, env)
+				if _, err := NewClientFromEnv(); err == nil {
+					t.Fatalf("Expect to get an error for %v.", env)
+				}
+			})
+		}
+	})
+}
+
+func TestSearchIteratorPagination(t *testing.T) {
+	type Result map[string]interface{}
+
+	// 250 documents whose years tie in groups of three, served one per page
+	count := 250
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query struct {
+			Page    app.Page `json:"page"`
+			Filters map[string]struct {
+				From *int `json:"from"`
+			} `json:"filters"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if query.Page.Current > 100 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["Page number must be less than or equal to 100"]}`))
+			return
+		}
+
+		var results []Result
+		for i := 0; i < count; i++ {
+			year := i / 3
+			if from := query.Filters["year"].From; from != nil && year < *from {
+				continue
+			}
+			id := fmt.Sprintf("doc-%03d", i)
+			results = append(results, Result{
+				"_meta": Result{"id": id, "score": 1},
+				"id":    Result{"raw": id},
+				"year":  Result{"raw": year},
+			})
+		}
+
+		total := len(results)
+		start := (query.Page.Current - 1) * query.Page.Size
+		end := start + query.Page.Size
+		if start > total {
+			start = total
+		}
+		if end > total {
+			end = total
+		}
+		json.NewEncoder(w).Encode(Result{
+			"meta": Result{"page": Result{
+				"current":       query.Page.Current,
+				"size":          query.Page.Size,
+				"total_pages":   (total + query.Page.Size - 1) / query.Page.Size,
+				"total_results": total,
+			}},
+			"results": results[start:end],
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Address: server.URL, DisableRetry: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	t.Run("stop at the pagination limit", func(t *testing.T) {
+		it := client.AppSearch.Search.Iterator(context.Background(), "national-parks", app.SearchQuery{
+			Page: &app.Page{Size: 1},
+		})
+
+		n := 0
+		for it.Next() {
+			n++
+		}
+		if !errors.Is(it.Err(), app.ErrPaginationLimit) {
+			t.Fatalf("Expect to get %s, but got %v.", app.ErrPaginationLimit, it.Err())
+		}
+		if n != 100 {
+			t.Fatalf("Expect to get 100 results before the limit, but got %d.", n)
+		}
+	})
+
+	t.Run("walk all results by partition", func(t *testing.T) {
+		it := client.AppSearch.Search.Iterator(context.Background(), "national-parks", app.SearchQuery{
+			Page: &app.Page{Size: 1},
+		})
+		it.PartitionField = "year"
+
+		ids := map[string]int{}
+		for it.Next() {
+			ids[it.Result().Meta.ID]++
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if len(ids) != count {
+			t.Fatalf("Expect to get %d documents, but got %d.", count, len(ids))
+		}
+		for id, n := range ids {
+			if n != 1 {
+				t.Fatalf("Expect to get document %s once, but got it %d times.", id, n)
+			}
+		}
+	})
+}
+
+func TestBulkIndexerAdd(t *testing.T) {
+	appAPI := app.New(transportFunc(func(req *http.Request) (*http.Response, error) {
+		// hold the request until it is cancelled
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}))
+
+	bi, err := app.NewBulkIndexer(app.BulkIndexerConfig{
+		Client:         appAPI,
+		Engine:         "national-parks",
+		NumWorkers:     1,
+		FlushDocuments: 1,
+		FlushBytes:     100,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	t.Run("reject a document exceeding the flush bytes", func(t *testing.T) {
+		err := bi.Add(context.Background(), app.BulkIndexerItem{
+			Document: map[string]interface{}{"name": strings.Repeat("a", 100)},
+		})
+		if err == nil {
+			t.Fatal("Expect to get an error for a document exceeding the flush bytes.")
+		}
+	})
+
+	t.Run("close while adding is blocked", func(t *testing.T) {
+		doc := app.BulkIndexerItem{Document: map[string]interface{}{"name": "Yosemite"}}
+		// the first is held by the worker and the second fills the queue
+		for i := 0; i < 2; i++ {
+			if err := bi.Add(context.Background(), doc); err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+		}
+
+		added := make(chan error)
+		go func() {
+			added <- bi.Add(context.Background(), doc)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := bi.Close(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("Expect to get %s, but got %v.", context.DeadlineExceeded, err)
+		}
+		if err := <-added; err != app.ErrBulkIndexerClosed {
+			t.Fatalf("Expect to get %s, but got %v.", app.ErrBulkIndexerClosed, err)
+		}
+
+		stats := bi.Stats()
+		if stats.NumAdded != 2 || stats.NumFailed != 2 {
+			t.Fatalf("Expect 2 documents added and failed, but got %+v.", stats)
+		}
+	})
+}
+
+func TestErrorResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/api/as/v1/engines/")
+		status, _ := strconv.Atoi(strings.SplitN(name, "-", 2)[0])
+		body := map[string]string{
+			"list":   `{"errors":["Could not find engine."]}`,
+			"fields": `{"errors":{"name":["is too long"],"language":["is not supported"]}}`,
+			"single": `{"error":"Too many requests"}`,
+			"text":   `Conflict`,
+		}[strings.SplitN(name, "-", 2)[1]]
+
+		w.Header().Set("X-Request-Id", "request-"+name)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Address:      server.URL,
+		DisableRetry: true,
+		ReturnErrors: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	for _, tt := range []struct {
+		engine   string
+		is       error
+		messages []string
+	}{
+		{"404-list", api.ErrNotFound, []string{"Could not find engine."}},
+		{"400-fields", nil, []string{"language: is not supported", "name: is too long"}},
+		{"429-single", api.ErrRateLimited, []string{"Too many requests"}},
+		{"409-text", api.ErrConflict, []string{"Conflict"}},
+		{"401-list", api.ErrUnauthorized, []string{"Could not find engine."}},
+		{"403-list", api.ErrUnauthorized, []string{"Could not find engine."}},
+	} {
+		tt := tt
+		t.Run(tt.engine, func(t *testing.T) {
+			_, err := client.AppSearch.Engines.Get(tt.engine)
+
+			var apiErr *api.Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expect to get an api.Error, but got %v.", err)
+			}
+			if apiErr.RequestID != "request-"+tt.engine {
+				t.Fatalf("Expect to get request id: request-%s, but got: %s.", tt.engine, apiErr.RequestID)
+			}
+			if fmt.Sprint(apiErr.Messages) != fmt.Sprint(tt.messages) {
+				t.Fatalf("Expect to get messages: %v, but got: %v.", tt.messages, apiErr.Messages)
+			}
+
+			for _, sentinel := range []error{api.ErrNotFound, api.ErrUnauthorized, api.ErrRateLimited, api.ErrConflict} {
+				if errors.Is(err, sentinel) != (sentinel == tt.is) {
+					t.Fatalf("Expect errors.Is(%s) to be %t.", sentinel, sentinel == tt.is)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryLimits(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		Address:       server.URL,
+		MaxRetryAfter: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		retry    *api.RetryConfig
+		requests int
+	}{
+		{"retry 3 times by default", nil, 4},
+		{"override the number of retries", &api.RetryConfig{MaxRetries: 1}, 2},
+		{"disable retrying", &api.RetryConfig{Disable: true}, 1},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.retry != nil {
+				ctx = api.ContextWithRetry(ctx, *tt.retry)
+			}
+
+			requests = 0
+			start := time.Now()
+			resp, err := client.Health(client.Health.WithContext(ctx))
+			if err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+			resp.Body.Close()
+
+			if requests != tt.requests {
+				t.Fatalf("Expect to send %d requests, but sent %d.", tt.requests, requests)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("Expect Retry-After to be capped, but waited %s.", elapsed)
+			}
+		})
+	}
+}
+
+func TestRetryNetworkErrors(t *testing.T) {
+	var requests int
+	fail := transportFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return nil, errors.New("connection reset by peer")
+	})
+
+	for _, tt := range []struct {
+		name     string
+		methods  []string
+		send     func(*Client) (*api.Response, error)
+		requests int
+	}{
+		{
+			name: "do not replay a post by default",
+			send: func(c *Client) (*api.Response, error) {
+				return c.AppSearch.Search(c.AppSearch.Search.WithEngine("national-parks"))
+			},
+			requests: 1,
+		},
+		{
+			name: "retry a get by default",
+			send: func(c *Client) (*api.Response, error) {
+				return c.AppSearch.Engines.Get("national-parks")
+			},
+			requests: 4,
+		},
+		{
+			name:    "retry a post when enabled",
+			methods: []string{http.MethodPost},
+			send: func(c *Client) (*api.Response, error) {
+				return c.AppSearch.Search(c.AppSearch.Search.WithEngine("national-parks"))
+			},
+			requests: 4,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(Config{
+				APITransport:   fail,
+				RetryOnMethods: tt.methods,
+				RetryBackoff:   func(int) time.Duration { return time.Millisecond },
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s\n", err)
+			}
+
+			requests = 0
+			if _, err := tt.send(client); err == nil {
+				t.Fatal("Expect to get a network error.")
+			}
+			if requests != tt.requests {
+				t.Fatalf("Expect to send %d requests, but sent %d.", tt.requests, requests)
+			}
+		})
+	}
 }